@@ -0,0 +1,179 @@
+// Copyright (c) 2026, Roel Schut. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package auth
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/go-pogo/errors"
+	"github.com/go-pogo/webapp/problem"
+)
+
+const (
+	ErrNoCredentials      errors.Msg = "no credentials provided"
+	ErrInvalidCredentials errors.Msg = "invalid credentials"
+)
+
+// Principal is the identity of an authenticated client.
+type Principal struct {
+	// Subject identifies the authenticated client, e.g. a username or the
+	// "sub" claim of a JWT.
+	Subject string
+	// Method is the name of the authentication method used to authenticate
+	// the client.
+	Method string
+	// Claims contains additional (provider specific) information about the
+	// principal.
+	Claims map[string]any
+}
+
+// Authenticator authenticates a request.
+type Authenticator interface {
+	// Authenticate returns the [Principal] of the authenticated request. It
+	// returns an error which matches [ErrNoCredentials] when the request does
+	// not contain any credentials supported by the [Authenticator], or an
+	// error which matches [ErrInvalidCredentials] when the provided
+	// credentials are invalid.
+	Authenticate(req *http.Request) (*Principal, error)
+}
+
+// Challenger is an optional interface which may be implemented by an
+// [Authenticator]. Its returned value is used as value of the
+// WWW-Authenticate header when authentication fails.
+type Challenger interface {
+	Challenge() string
+}
+
+// AuthenticatorFunc is a function that implements [Authenticator].
+type AuthenticatorFunc func(req *http.Request) (*Principal, error)
+
+func (fn AuthenticatorFunc) Authenticate(req *http.Request) (*Principal, error) {
+	return fn(req)
+}
+
+// Any returns an [Authenticator] which tries each of the provided
+// [Authenticator]s in order and returns the first authenticated [Principal].
+func Any(auths ...Authenticator) Authenticator { return anyOf(auths) }
+
+type anyOf []Authenticator
+
+func (a anyOf) Authenticate(req *http.Request) (*Principal, error) {
+	for _, auth := range a {
+		p, err := auth.Authenticate(req)
+		if err == nil {
+			return p, nil
+		}
+		if !errors.Is(err, ErrNoCredentials) {
+			return nil, err
+		}
+	}
+	return nil, errors.New(ErrNoCredentials)
+}
+
+func (a anyOf) Challenge() string {
+	for _, auth := range a {
+		if c, ok := auth.(Challenger); ok {
+			return c.Challenge()
+		}
+	}
+	return ""
+}
+
+// Middleware returns a middleware function which wraps the provided next
+// [http.Handler] using [Handler].
+func Middleware(auth Authenticator) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return Handler(auth, next)
+	}
+}
+
+// Handler authenticates each request using the provided [Authenticator]
+// before calling next. The authenticated [Principal] is added to the request's
+// context. Requests that fail authentication, including those for which no
+// [Principal] is returned, are responded to with [http.StatusUnauthorized]
+// problem details, see [problem.Write].
+func Handler(auth Authenticator, next http.Handler) http.Handler {
+	var challenge string
+	if c, ok := auth.(Challenger); ok {
+		challenge = c.Challenge()
+	}
+
+	return http.HandlerFunc(func(wri http.ResponseWriter, req *http.Request) {
+		p, err := auth.Authenticate(req)
+		if err == nil && p == nil {
+			// treat a misbehaving Authenticator as unauthenticated
+			err = errors.New(ErrNoCredentials)
+		}
+		if err != nil {
+			if challenge != "" {
+				wri.Header().Set("WWW-Authenticate", challenge)
+			}
+			_ = problem.Write(wri, req, unauthorized(err))
+			return
+		}
+
+		next.ServeHTTP(wri, req.WithContext(ContextWithPrincipal(req.Context(), *p)))
+	})
+}
+
+// unauthorized returns the [problem.Details] of a failed authentication. Only
+// the messages of [ErrNoCredentials] and [ErrInvalidCredentials] are exposed.
+func unauthorized(err error) *problem.Details {
+	var detail string
+	switch {
+	case errors.Is(err, ErrNoCredentials):
+		detail = ErrNoCredentials.Error()
+	case errors.Is(err, ErrInvalidCredentials):
+		detail = ErrInvalidCredentials.Error()
+	}
+	return problem.New(http.StatusUnauthorized, detail)
+}
+
+type ctxPrincipalKey struct{}
+
+// principalValue is stored in a context. It holds the [Principal] once the
+// request is authenticated.
+type principalValue struct {
+	Principal
+	authenticated bool
+}
+
+// ContextWithPrincipal adds a [Principal] to the context. It returns a derived
+// context that points to the parent [context.Context] when a [Principal] is
+// not already added. Otherwise, it will update the previously added
+// [Principal] with p and return the context as is.
+func ContextWithPrincipal(ctx context.Context, p Principal) context.Context {
+	if v, ok := ctx.Value(ctxPrincipalKey{}).(*principalValue); ok {
+		v.Principal, v.authenticated = p, true
+		return ctx
+	}
+	return context.WithValue(ctx, ctxPrincipalKey{}, &principalValue{
+		Principal:     p,
+		authenticated: true,
+	})
+}
+
+// PrincipalFromContext returns the authenticated [Principal] from the context
+// values, or nil when the request is not authenticated.
+func PrincipalFromContext(ctx context.Context) *Principal {
+	if v, ok := ctx.Value(ctxPrincipalKey{}).(*principalValue); ok && v.authenticated {
+		return &v.Principal
+	}
+	return nil
+}
+
+// AddPrincipal adds an empty [Principal] to the request's context, which is
+// updated once the request is authenticated by a [Handler] further down the
+// chain. This makes the [Principal] available to handlers which wrap that
+// [Handler], such as an access logger.
+func AddPrincipal(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(wri http.ResponseWriter, req *http.Request) {
+		if _, ok := req.Context().Value(ctxPrincipalKey{}).(*principalValue); !ok {
+			req = req.WithContext(context.WithValue(req.Context(), ctxPrincipalKey{}, new(principalValue)))
+		}
+		next.ServeHTTP(wri, req)
+	})
+}
@@ -0,0 +1,128 @@
+// Copyright (c) 2026, Roel Schut. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package auth
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-pogo/webapp/problem"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandler(t *testing.T) {
+	auth := Any(
+		&Basic{Users: map[string]string{"user": "secret"}},
+		&Bearer{Tokens: map[string]string{"token": "service"}},
+	)
+
+	var have *Principal
+	handler := Handler(auth, http.HandlerFunc(func(_ http.ResponseWriter, req *http.Request) {
+		have = PrincipalFromContext(req.Context())
+	}))
+
+	tests := map[string]struct {
+		setup       func(req *http.Request)
+		wantCode    int
+		wantSubject string
+	}{
+		"no credentials": {
+			setup:    func(*http.Request) {},
+			wantCode: http.StatusUnauthorized,
+		},
+		"basic": {
+			setup:       func(req *http.Request) { req.SetBasicAuth("user", "secret") },
+			wantCode:    http.StatusOK,
+			wantSubject: "user",
+		},
+		"invalid basic": {
+			setup:    func(req *http.Request) { req.SetBasicAuth("user", "wrong") },
+			wantCode: http.StatusUnauthorized,
+		},
+		"bearer": {
+			setup:       func(req *http.Request) { req.Header.Set("Authorization", "Bearer token") },
+			wantCode:    http.StatusOK,
+			wantSubject: "service",
+		},
+		"invalid bearer": {
+			setup:    func(req *http.Request) { req.Header.Set("Authorization", "Bearer nope") },
+			wantCode: http.StatusUnauthorized,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			have = nil
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			tc.setup(req)
+
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+			assert.Equal(t, tc.wantCode, rec.Code)
+
+			if tc.wantSubject == "" {
+				assert.Nil(t, have)
+				assert.NotEmpty(t, rec.Header().Get("WWW-Authenticate"))
+				assert.Equal(t, problem.ContentType, rec.Header().Get("Content-Type"))
+			} else if assert.NotNil(t, have) {
+				assert.Equal(t, tc.wantSubject, have.Subject)
+			}
+		})
+	}
+}
+
+func TestHandler_nilPrincipal(t *testing.T) {
+	auth := AuthenticatorFunc(func(*http.Request) (*Principal, error) {
+		return nil, nil
+	})
+
+	var called bool
+	rec := httptest.NewRecorder()
+	Handler(auth, http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		called = true
+	})).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	assert.False(t, called)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	assert.Equal(t, problem.ContentType, rec.Header().Get("Content-Type"))
+}
+
+func TestAddPrincipal(t *testing.T) {
+	auth := &Bearer{Tokens: map[string]string{"token": "service"}}
+
+	var have *Principal
+	handler := AddPrincipal(http.HandlerFunc(func(wri http.ResponseWriter, req *http.Request) {
+		Handler(auth, http.NotFoundHandler()).ServeHTTP(wri, req)
+		// principal is available outside the auth handler
+		have = PrincipalFromContext(req.Context())
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Authorization", "Bearer token")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	if assert.NotNil(t, have) {
+		assert.Equal(t, "service", have.Subject)
+		assert.Equal(t, MethodBearer, have.Method)
+	}
+}
+
+func TestPrincipalFromContext(t *testing.T) {
+	assert.Nil(t, PrincipalFromContext(context.Background()))
+
+	var have *Principal
+	handler := AddPrincipal(http.HandlerFunc(func(_ http.ResponseWriter, req *http.Request) {
+		assert.Nil(t, PrincipalFromContext(req.Context()), "not authenticated yet")
+		ctx := ContextWithPrincipal(req.Context(), Principal{Method: "anonymous"})
+		have = PrincipalFromContext(ctx)
+	}))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if assert.NotNil(t, have, "principal without subject") {
+		assert.Equal(t, "anonymous", have.Method)
+	}
+}
@@ -0,0 +1,107 @@
+// Copyright (c) 2026, Roel Schut. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package auth
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-pogo/errors"
+)
+
+const (
+	MethodBasic  = "basic"
+	MethodBearer = "bearer"
+)
+
+var _ Challenger = (*Basic)(nil)
+
+// Basic authenticates requests using HTTP basic authentication.
+type Basic struct {
+	// Realm is used in the WWW-Authenticate header.
+	Realm string
+	// Users contains the usernames and their passwords.
+	Users map[string]string
+}
+
+// Authenticate is part of the [Authenticator] interface.
+func (b *Basic) Authenticate(req *http.Request) (*Principal, error) {
+	user, pass, ok := req.BasicAuth()
+	if !ok {
+		return nil, errors.New(ErrNoCredentials)
+	}
+
+	want, exists := b.Users[user]
+	// always compare to prevent leaking the existence of users via timing
+	if !equal(pass, want) || !exists {
+		return nil, errors.New(ErrInvalidCredentials)
+	}
+
+	return &Principal{
+		Subject: user,
+		Method:  MethodBasic,
+	}, nil
+}
+
+// Challenge is part of the [Challenger] interface.
+func (b *Basic) Challenge() string {
+	realm := b.Realm
+	if realm == "" {
+		realm = "restricted"
+	}
+	return "Basic realm=" + strconv.Quote(realm) + `, charset="UTF-8"`
+}
+
+var _ Challenger = (*Bearer)(nil)
+
+// Bearer authenticates requests which contain one of the static bearer Tokens
+// in their Authorization header.
+type Bearer struct {
+	// Tokens maps each valid token to the subject of its [Principal].
+	Tokens map[string]string
+}
+
+// Authenticate is part of the [Authenticator] interface.
+func (b *Bearer) Authenticate(req *http.Request) (*Principal, error) {
+	token, ok := BearerToken(req)
+	if !ok {
+		return nil, errors.New(ErrNoCredentials)
+	}
+
+	for t, subject := range b.Tokens {
+		if equal(token, t) {
+			return &Principal{
+				Subject: subject,
+				Method:  MethodBearer,
+			}, nil
+		}
+	}
+	return nil, errors.New(ErrInvalidCredentials)
+}
+
+// Challenge is part of the [Challenger] interface.
+func (*Bearer) Challenge() string { return "Bearer" }
+
+// BearerToken returns the bearer token from the request's Authorization
+// header.
+func BearerToken(req *http.Request) (string, bool) {
+	const prefix = "bearer "
+
+	h := req.Header.Get("Authorization")
+	if len(h) <= len(prefix) || !strings.EqualFold(h[:len(prefix)], prefix) {
+		return "", false
+	}
+	return strings.TrimSpace(h[len(prefix):]), true
+}
+
+// equal compares a and b in constant time.
+func equal(a, b string) bool {
+	ah := sha256.Sum256([]byte(a))
+	bh := sha256.Sum256([]byte(b))
+	return subtle.ConstantTimeCompare(ah[:], bh[:]) == 1
+}
@@ -0,0 +1,259 @@
+// Copyright (c) 2026, Roel Schut. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"math/big"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/go-pogo/errors"
+)
+
+const (
+	ErrUnknownKey       errors.Msg = "unknown key"
+	ErrInvalidKey       errors.Msg = "invalid key"
+	ErrJWKSUnavailable  errors.Msg = "unable to load jwks"
+	ErrNoJWKSSource     errors.Msg = "jwks file or url is required"
+	ErrMultiJWKSSources errors.Msg = "jwks file and url are mutually exclusive"
+	ErrKeyAlgorithm     errors.Msg = "algorithm not allowed for key"
+)
+
+// jwk is a single JSON Web Key, see RFC 7517.
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	// RSA
+	N string `json:"n"`
+	E string `json:"e"`
+	// EC and OKP
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+type jwks struct {
+	Keys []jwk `json:"keys"`
+}
+
+// verifyKey is a loaded key of a JSON Web Key Set.
+type verifyKey struct {
+	crypto.PublicKey
+	// alg is the algorithm the key must be used with. Any algorithm which
+	// matches the key's type is allowed when empty.
+	alg string
+}
+
+// keySet loads and caches the keys of a JSON Web Key Set from a file or url.
+// Keys are reloaded once they are older than refresh, or when an unknown key
+// id is requested and the last load was at least minRefresh ago. After a
+// failed load, no reload is attempted for minRefresh. Keys are loaded without
+// holding mut, so requests with known keys are never blocked by a reload.
+type keySet struct {
+	file       string
+	url        string
+	client     *http.Client
+	refresh    time.Duration
+	minRefresh time.Duration
+
+	loadMut  sync.Mutex // serializes reloads
+	mut      sync.RWMutex
+	keys     map[string]verifyKey
+	loadedAt time.Time
+	failedAt time.Time
+}
+
+func (ks *keySet) key(ctx context.Context, kid string) (verifyKey, error) {
+	ks.mut.RLock()
+	key, ok := ks.keys[kid]
+	stale := ks.refresh > 0 && time.Since(ks.loadedAt) > ks.refresh
+	ks.mut.RUnlock()
+
+	if ok && !stale {
+		return key, nil
+	}
+
+	if err := ks.reload(ctx, !ok); err != nil && !ok {
+		return verifyKey{}, err
+	}
+
+	ks.mut.RLock()
+	defer ks.mut.RUnlock()
+	if key, ok = ks.keys[kid]; !ok {
+		return verifyKey{}, errors.New(ErrUnknownKey)
+	}
+	return key, nil
+}
+
+func (ks *keySet) reload(ctx context.Context, unknownKid bool) error {
+	if unknownKid {
+		ks.loadMut.Lock()
+	} else if !ks.loadMut.TryLock() {
+		// already reloading in another goroutine, keep using the current keys
+		return nil
+	}
+	defer ks.loadMut.Unlock()
+
+	ks.mut.RLock()
+	loadedAt, failedAt := ks.loadedAt, ks.failedAt
+	ks.mut.RUnlock()
+
+	if !failedAt.IsZero() && time.Since(failedAt) < ks.minRefresh {
+		// prevent retrying a failed load on every request
+		return nil
+	}
+
+	since := time.Since(loadedAt)
+	if unknownKid && !loadedAt.IsZero() && since < ks.minRefresh {
+		// prevent clients from triggering a reload on every request
+		return nil
+	}
+	if !unknownKid && ks.refresh > 0 && since <= ks.refresh {
+		// already reloaded by another goroutine
+		return nil
+	}
+
+	keys, err := ks.load(ctx)
+	if err != nil {
+		// keep using the previously loaded keys
+		ks.mut.Lock()
+		ks.failedAt = time.Now()
+		ks.mut.Unlock()
+		return err
+	}
+
+	ks.mut.Lock()
+	ks.keys = keys
+	ks.loadedAt = time.Now()
+	ks.failedAt = time.Time{}
+	ks.mut.Unlock()
+	return nil
+}
+
+func (ks *keySet) load(ctx context.Context) (map[string]verifyKey, error) {
+	var data []byte
+	var err error
+	if ks.file != "" {
+		data, err = os.ReadFile(ks.file)
+	} else {
+		data, err = ks.fetch(ctx)
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, ErrJWKSUnavailable)
+	}
+
+	var set jwks
+	if err = json.Unmarshal(data, &set); err != nil {
+		return nil, errors.Wrap(err, ErrJWKSUnavailable)
+	}
+
+	keys := make(map[string]verifyKey, len(set.Keys))
+	for _, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+
+		key, err := k.publicKey()
+		if err != nil {
+			// skip unsupported keys, e.g. symmetric keys or other curves, so
+			// the supported keys of the set can still be used
+			continue
+		}
+		keys[k.Kid] = verifyKey{PublicKey: key, alg: k.Alg}
+	}
+	return keys, nil
+}
+
+func (ks *keySet) fetch(ctx context.Context) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, ks.url, nil)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	client := ks.client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+}
+
+func (k jwk) publicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err1 := decodeBigInt(k.N)
+		e, err2 := decodeBigInt(k.E)
+		if err := errors.Join(err1, err2); err != nil {
+			return nil, err
+		}
+		if !e.IsInt64() {
+			return nil, errors.New(ErrInvalidKey)
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+
+	case "EC":
+		var crv elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			crv = elliptic.P256()
+		case "P-384":
+			crv = elliptic.P384()
+		case "P-521":
+			crv = elliptic.P521()
+		default:
+			return nil, errors.New(ErrInvalidKey)
+		}
+
+		x, err1 := decodeBigInt(k.X)
+		y, err2 := decodeBigInt(k.Y)
+		if err := errors.Join(err1, err2); err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{Curve: crv, X: x, Y: y}, nil
+
+	case "OKP":
+		if k.Crv != "Ed25519" {
+			return nil, errors.New(ErrInvalidKey)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil || len(x) != ed25519.PublicKeySize {
+			return nil, errors.New(ErrInvalidKey)
+		}
+		return ed25519.PublicKey(x), nil
+
+	default:
+		return nil, errors.New(ErrInvalidKey)
+	}
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil || len(b) == 0 {
+		return nil, errors.New(ErrInvalidKey)
+	}
+	return new(big.Int).SetBytes(b), nil
+}
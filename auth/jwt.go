@@ -0,0 +1,270 @@
+// Copyright (c) 2026, Roel Schut. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"hash"
+	"math/big"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/go-pogo/errors"
+)
+
+const MethodJWT = "jwt"
+
+const (
+	ErrMalformedToken       errors.Msg = "malformed token"
+	ErrUnsupportedAlgorithm errors.Msg = "unsupported algorithm"
+	ErrInvalidSignature     errors.Msg = "invalid signature"
+	ErrTokenExpired         errors.Msg = "token is expired"
+	ErrTokenNotYetValid     errors.Msg = "token is not yet valid"
+	ErrInvalidIssuer        errors.Msg = "invalid issuer"
+	ErrInvalidAudience      errors.Msg = "invalid audience"
+)
+
+// JWTConfig is the configuration used by [NewJWT].
+type JWTConfig struct {
+	// JWKSFile is the path to a file containing a JSON Web Key Set.
+	JWKSFile string `env:"AUTH_JWKS_FILE"`
+	// JWKSURL is the url to load the JSON Web Key Set from.
+	JWKSURL string `env:"AUTH_JWKS_URL"`
+	// RefreshInterval is the interval after which the JSON Web Key Set is
+	// reloaded to pick up rotated keys.
+	RefreshInterval time.Duration `env:"AUTH_JWKS_REFRESH_INTERVAL" default:"1h"`
+	// MinRefreshInterval is the minimum interval between reloads that are
+	// triggered by tokens signed with an unknown key.
+	MinRefreshInterval time.Duration `env:"AUTH_JWKS_MIN_REFRESH_INTERVAL" default:"1m"`
+	// Issuer, when not empty, must match the "iss" claim.
+	Issuer string `env:"AUTH_JWT_ISSUER"`
+	// Audience, when not empty, must be present in the "aud" claim.
+	Audience string `env:"AUTH_JWT_AUDIENCE"`
+	// Leeway is the allowed clock skew when validating time based claims.
+	Leeway time.Duration `env:"AUTH_JWT_LEEWAY" default:"1m"`
+}
+
+var _ Challenger = (*JWT)(nil)
+
+// JWT authenticates requests containing a JSON Web Token as bearer token.
+// The token's signature is validated using the keys from a JSON Web Key Set.
+type JWT struct {
+	conf JWTConfig
+	keys *keySet
+}
+
+// NewJWT returns a new [JWT] [Authenticator] which loads its JSON Web Key Set
+// from either [JWTConfig.JWKSFile] or [JWTConfig.JWKSURL]. The provided
+// [http.Client] is used to fetch the keys from the url, it defaults to
+// [http.DefaultClient] when nil.
+func NewJWT(conf JWTConfig, client *http.Client) (*JWT, error) {
+	if conf.JWKSFile == "" && conf.JWKSURL == "" {
+		return nil, errors.New(ErrNoJWKSSource)
+	}
+	if conf.JWKSFile != "" && conf.JWKSURL != "" {
+		return nil, errors.New(ErrMultiJWKSSources)
+	}
+
+	j := &JWT{
+		conf: conf,
+		keys: &keySet{
+			file:       conf.JWKSFile,
+			url:        conf.JWKSURL,
+			client:     client,
+			refresh:    conf.RefreshInterval,
+			minRefresh: conf.MinRefreshInterval,
+		},
+	}
+
+	keys, err := j.keys.load(context.Background())
+	if err != nil {
+		return nil, err
+	}
+
+	j.keys.keys = keys
+	j.keys.loadedAt = time.Now()
+	return j, nil
+}
+
+// Challenge is part of the [Challenger] interface.
+func (*JWT) Challenge() string { return "Bearer" }
+
+// Authenticate is part of the [Authenticator] interface. The returned
+// [Principal] contains the token's claims.
+func (j *JWT) Authenticate(req *http.Request) (*Principal, error) {
+	token, ok := BearerToken(req)
+	if !ok {
+		return nil, errors.New(ErrNoCredentials)
+	}
+
+	claims, err := j.verify(req, token)
+	if err != nil {
+		return nil, errors.Wrap(err, ErrInvalidCredentials)
+	}
+
+	sub, _ := claims["sub"].(string)
+	return &Principal{
+		Subject: sub,
+		Method:  MethodJWT,
+		Claims:  claims,
+	}, nil
+}
+
+type jwtHeader struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+}
+
+func (j *JWT) verify(req *http.Request, token string) (map[string]any, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, errors.New(ErrMalformedToken)
+	}
+
+	var head jwtHeader
+	if err := decodeSegment(parts[0], &head); err != nil {
+		return nil, err
+	}
+
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, errors.New(ErrMalformedToken)
+	}
+
+	key, err := j.keys.key(req.Context(), head.Kid)
+	if err != nil {
+		return nil, err
+	}
+	if key.alg != "" && key.alg != head.Alg {
+		return nil, errors.New(ErrKeyAlgorithm)
+	}
+	if err = verifySignature(head.Alg, key.PublicKey, parts[0]+"."+parts[1], sig); err != nil {
+		return nil, err
+	}
+
+	var claims map[string]any
+	if err = decodeSegment(parts[1], &claims); err != nil {
+		return nil, err
+	}
+	if err = j.validate(claims); err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+func (j *JWT) validate(claims map[string]any) error {
+	now := time.Now()
+	if exp, ok := numericDate(claims["exp"]); ok && now.After(exp.Add(j.conf.Leeway)) {
+		return errors.New(ErrTokenExpired)
+	}
+	if nbf, ok := numericDate(claims["nbf"]); ok && now.Before(nbf.Add(-j.conf.Leeway)) {
+		return errors.New(ErrTokenNotYetValid)
+	}
+	if j.conf.Issuer != "" {
+		if iss, _ := claims["iss"].(string); iss != j.conf.Issuer {
+			return errors.New(ErrInvalidIssuer)
+		}
+	}
+	if j.conf.Audience != "" && !hasAudience(claims["aud"], j.conf.Audience) {
+		return errors.New(ErrInvalidAudience)
+	}
+	return nil
+}
+
+func decodeSegment(seg string, v any) error {
+	b, err := base64.RawURLEncoding.DecodeString(seg)
+	if err != nil {
+		return errors.New(ErrMalformedToken)
+	}
+	if err = json.Unmarshal(b, v); err != nil {
+		return errors.Wrap(err, ErrMalformedToken)
+	}
+	return nil
+}
+
+func numericDate(v any) (time.Time, bool) {
+	f, ok := v.(float64)
+	if !ok {
+		return time.Time{}, false
+	}
+	return time.Unix(int64(f), 0), true
+}
+
+func hasAudience(v any, want string) bool {
+	switch aud := v.(type) {
+	case string:
+		return aud == want
+	case []any:
+		return slices.Contains(aud, any(want))
+	default:
+		return false
+	}
+}
+
+func verifySignature(alg string, key crypto.PublicKey, signed string, sig []byte) error {
+	if alg == "EdDSA" {
+		k, ok := key.(ed25519.PublicKey)
+		if !ok || !ed25519.Verify(k, []byte(signed), sig) {
+			return errors.New(ErrInvalidSignature)
+		}
+		return nil
+	}
+
+	if len(alg) != 5 {
+		return errors.New(ErrUnsupportedAlgorithm)
+	}
+
+	var h hash.Hash
+	var ch crypto.Hash
+	var crv elliptic.Curve // the curve of the ES algorithm
+	switch alg[2:] {
+	case "256":
+		h, ch, crv = sha256.New(), crypto.SHA256, elliptic.P256()
+	case "384":
+		h, ch, crv = sha512.New384(), crypto.SHA384, elliptic.P384()
+	case "512":
+		h, ch, crv = sha512.New(), crypto.SHA512, elliptic.P521()
+	default:
+		return errors.New(ErrUnsupportedAlgorithm)
+	}
+	h.Write([]byte(signed))
+	digest := h.Sum(nil)
+
+	var valid bool
+	switch alg[:2] {
+	case "RS":
+		k, ok := key.(*rsa.PublicKey)
+		valid = ok && rsa.VerifyPKCS1v15(k, ch, digest, sig) == nil
+	case "PS":
+		k, ok := key.(*rsa.PublicKey)
+		valid = ok && rsa.VerifyPSS(k, ch, digest, sig, nil) == nil
+	case "ES":
+		k, ok := key.(*ecdsa.PublicKey)
+		if !ok || k.Curve != crv || len(sig) != 2*((crv.Params().BitSize+7)/8) {
+			break
+		}
+		r := new(big.Int).SetBytes(sig[:len(sig)/2])
+		s := new(big.Int).SetBytes(sig[len(sig)/2:])
+		valid = ecdsa.Verify(k, digest, r, s)
+	default:
+		return errors.New(ErrUnsupportedAlgorithm)
+	}
+
+	if !valid {
+		return errors.New(ErrInvalidSignature)
+	}
+	return nil
+}
@@ -0,0 +1,238 @@
+// Copyright (c) 2026, Roel Schut. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJWT_Authenticate(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	file := filepath.Join(t.TempDir(), "jwks.json")
+	writeJWKS(t, file, "key1", &key.PublicKey)
+
+	auth, err := NewJWT(JWTConfig{
+		JWKSFile: file,
+		Issuer:   "issuer",
+		Audience: "webapp",
+	}, nil)
+	require.NoError(t, err)
+
+	tests := map[string]struct {
+		kid     string
+		claims  map[string]any
+		wantErr error
+	}{
+		"valid": {
+			kid: "key1",
+			claims: map[string]any{
+				"sub": "user",
+				"iss": "issuer",
+				"aud": []string{"other", "webapp"},
+				"exp": time.Now().Add(time.Hour).Unix(),
+			},
+		},
+		"expired": {
+			kid: "key1",
+			claims: map[string]any{
+				"sub": "user",
+				"iss": "issuer",
+				"aud": "webapp",
+				"exp": time.Now().Add(-time.Hour).Unix(),
+			},
+			wantErr: ErrTokenExpired,
+		},
+		"invalid issuer": {
+			kid: "key1",
+			claims: map[string]any{
+				"sub": "user",
+				"iss": "other",
+				"aud": "webapp",
+			},
+			wantErr: ErrInvalidIssuer,
+		},
+		"unknown key": {
+			kid:     "key2",
+			claims:  map[string]any{"sub": "user"},
+			wantErr: ErrUnknownKey,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header.Set("Authorization", "Bearer "+signRS256(t, key, tc.kid, tc.claims))
+
+			have, err := auth.Authenticate(req)
+			if tc.wantErr != nil {
+				assert.ErrorIs(t, err, tc.wantErr)
+				assert.ErrorIs(t, err, ErrInvalidCredentials)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, "user", have.Subject)
+			assert.Equal(t, MethodJWT, have.Method)
+		})
+	}
+
+	t.Run("rotated key", func(t *testing.T) {
+		key2, err := rsa.GenerateKey(rand.Reader, 2048)
+		require.NoError(t, err)
+		writeJWKS(t, file, "key2", &key2.PublicKey)
+		auth.keys.loadedAt = time.Time{}
+
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("Authorization", "Bearer "+signRS256(t, key2, "key2", map[string]any{
+			"sub": "user",
+			"iss": "issuer",
+			"aud": "webapp",
+		}))
+
+		_, err = auth.Authenticate(req)
+		assert.NoError(t, err)
+	})
+}
+
+func TestJWT_keys(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	require.NoError(t, err)
+
+	psKey := rsaJWK("rsa", &rsaKey.PublicKey)
+	psKey["alg"] = "PS256"
+
+	file := filepath.Join(t.TempDir(), "jwks.json")
+	writeKeys(t, file,
+		map[string]string{"kty": "oct", "kid": "secret", "k": "c2VjcmV0"},
+		map[string]string{"kty": "OKP", "kid": "x25519", "crv": "X25519", "x": "AAAA"},
+		psKey,
+		map[string]string{
+			"kty": "EC",
+			"kid": "ec",
+			"crv": "P-384",
+			"x":   base64.RawURLEncoding.EncodeToString(ecKey.X.Bytes()),
+			"y":   base64.RawURLEncoding.EncodeToString(ecKey.Y.Bytes()),
+		},
+	)
+
+	auth, err := NewJWT(JWTConfig{JWKSFile: file}, nil)
+	require.NoError(t, err, "unsupported keys must be skipped")
+	assert.Len(t, auth.keys.keys, 2)
+
+	t.Run("alg mismatch", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("Authorization", "Bearer "+signRS256(t, rsaKey, "rsa", map[string]any{"sub": "user"}))
+
+		_, err := auth.Authenticate(req)
+		assert.ErrorIs(t, err, ErrKeyAlgorithm)
+	})
+	t.Run("curve mismatch", func(t *testing.T) {
+		head, err := json.Marshal(map[string]string{"alg": "ES256", "kid": "ec"})
+		require.NoError(t, err)
+		signed := base64.RawURLEncoding.EncodeToString(head) + "." +
+			base64.RawURLEncoding.EncodeToString([]byte(`{"sub":"user"}`))
+
+		digest := sha256.Sum256([]byte(signed))
+		r, s, err := ecdsa.Sign(rand.Reader, ecKey, digest[:])
+		require.NoError(t, err)
+		sig := make([]byte, 96)
+		r.FillBytes(sig[:48])
+		s.FillBytes(sig[48:])
+
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("Authorization", "Bearer "+signed+"."+base64.RawURLEncoding.EncodeToString(sig))
+
+		_, err = auth.Authenticate(req)
+		assert.ErrorIs(t, err, ErrInvalidSignature)
+	})
+}
+
+func TestJWT_failedReload(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	data, err := json.Marshal(map[string]any{"keys": []map[string]string{rsaJWK("key1", &key.PublicKey)}})
+	require.NoError(t, err)
+
+	var fetches atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(wri http.ResponseWriter, _ *http.Request) {
+		if fetches.Add(1) > 1 {
+			wri.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		_, _ = wri.Write(data)
+	}))
+	defer srv.Close()
+
+	auth, err := NewJWT(JWTConfig{JWKSURL: srv.URL, MinRefreshInterval: time.Minute}, srv.Client())
+	require.NoError(t, err)
+	auth.keys.loadedAt = time.Time{}
+
+	for i := 0; i < 3; i++ {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("Authorization", "Bearer "+signRS256(t, key, "key2", map[string]any{"sub": "user"}))
+
+		_, err = auth.Authenticate(req)
+		assert.ErrorIs(t, err, ErrInvalidCredentials)
+	}
+	assert.Equal(t, int32(2), fetches.Load(), "a failed reload must not be retried immediately")
+}
+
+func writeJWKS(t *testing.T, file, kid string, key *rsa.PublicKey) {
+	writeKeys(t, file, rsaJWK(kid, key))
+}
+
+func writeKeys(t *testing.T, file string, keys ...map[string]string) {
+	data, err := json.Marshal(map[string]any{"keys": keys})
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(file, data, 0o600))
+}
+
+func rsaJWK(kid string, key *rsa.PublicKey) map[string]string {
+	return map[string]string{
+		"kty": "RSA",
+		"kid": kid,
+		"use": "sig",
+		"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+		"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+	}
+}
+
+func signRS256(t *testing.T, key *rsa.PrivateKey, kid string, claims map[string]any) string {
+	head, err := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT", "kid": kid})
+	require.NoError(t, err)
+	body, err := json.Marshal(claims)
+	require.NoError(t, err)
+
+	signed := base64.RawURLEncoding.EncodeToString(head) + "." +
+		base64.RawURLEncoding.EncodeToString(body)
+
+	digest := sha256.Sum256([]byte(signed))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	require.NoError(t, err)
+
+	return signed + "." + base64.RawURLEncoding.EncodeToString(sig)
+}
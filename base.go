@@ -7,6 +7,7 @@ package webapp
 import (
 	"context"
 	"net/http"
+	"time"
 
 	"github.com/go-pogo/buildinfo"
 	"github.com/go-pogo/easytls"
@@ -16,8 +17,14 @@ import (
 	"github.com/go-pogo/serv"
 	"github.com/go-pogo/serv/accesslog"
 	"github.com/go-pogo/telemetry"
+	"github.com/go-pogo/webapp/auth"
 	"github.com/go-pogo/webapp/logger"
+	"github.com/go-pogo/webapp/problem"
 	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/propagation"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+	"go.opentelemetry.io/otel/trace"
 )
 
 const (
@@ -39,56 +46,131 @@ type Logger interface {
 var _ healthcheck.HealthChecker = (*Base)(nil)
 
 type Base struct {
-	build  *buildinfo.BuildInfo
-	telem  *telemetry.Telemetry
-	health *healthcheck.Checker
-	router *router
-	server serv.Server
+	build *buildinfo.BuildInfo
+	telem *telemetry.Telemetry
+	logs  *sdklog.LoggerProvider
+	// callbacks are the registrations of observable metrics callbacks, they
+	// are unregistered on shutdown
+	callbacks []metric.Registration
+	health    *healthcheck.Checker
+	router    *router
+	server    serv.Server
+	log       Logger
+	created   time.Time
+	// lifecycle is the span which lasts from the creation of Base until its
+	// shutdown
+	lifecycle      trace.Span
+	healthDuration metric.Float64Histogram
+	// propagator is set by WithPropagation, when nil the global propagator is
+	// used
+	propagator propagation.TextMapPropagator
+	// runSpan is the span of Run until the server is started
+	runSpan trace.Span
 }
 
 func New(opts ...Option) (*Base, error) {
 	base := &Base{
-		router: &router{ServeMux: serv.NewServeMux()},
+		router:  newRouter(),
+		created: time.Now(),
 	}
 
-	// apply options
+	// apply options, their application is traced once telemetry is set up
 	var conf config
 	var err error
+	applied := make([]appliedOption, 0, len(opts))
 	for _, opt := range opts {
 		if opt == nil {
 			continue
 		}
-		err = errors.Append(err, opt(base, &conf))
+
+		a := appliedOption{name: funcName(opt), start: time.Now()}
+		a.err = opt(base, &conf)
+		a.end = time.Now()
+		applied = append(applied, a)
+		err = errors.Append(err, a.err)
+	}
+	optsEnd := time.Now()
+	// add errors of invalid routes registered by options
+	err = errors.Append(err, base.router.Err())
+	for name, check := range conf.healthChecks {
+		base.Register(name, check)
+	}
+	if err == nil {
+		err = errors.Append(base.setupTelemetry(&conf), base.setupOTELLogs(&conf))
 	}
 	if err != nil {
 		return nil, errors.Wrap(err, ErrApplyOptions)
 	}
 
+	span := base.traceNew(applied, optsEnd)
+	defer span.End()
+
+	if s, ok := conf.logger.(logger.BaggageFieldsSetter); ok && len(conf.baggage) != 0 {
+		s.SetBaggageFields(conf.baggage...)
+	}
+
 	// setup server
 	if err = base.server.With(
 		conf.server.Port,
-		serv.WithLogger(conf.servLogger()),
-		serv.WithTLSConfig(easytls.DefaultTLSConfig(), conf.server.TLS),
+		serv.WithLogger(&serverLogger{Logger: conf.servLogger(), base: base}),
+		serv.WithTLSConfig(easytls.DefaultTLSConfig(), conf.server.TLS, conf.hostCerts),
 		serv.With(conf.servOpts),
 	); err != nil {
-		return nil, errors.Wrap(err, ErrSetupServer)
+		err = errors.Wrap(err, ErrSetupServer)
+		endSpan(span, err)
+		return nil, err
 	}
 
 	// wrap router
-	var handler http.Handler = base.router
+	hl, _ := conf.logger.(logger.HandlerErrorLogger)
+	var handler http.Handler = addErrorHandling(errorHandling{
+		renderer: conf.renderer,
+		log:      hl,
+	}, base.router)
+	if l, ok := conf.logger.(*logger.Logger); ok {
+		// make the logger available via logger.FromContext
+		handler = logger.AddLogger(l, handler)
+	}
+	if conf.problems {
+		pl, _ := conf.logger.(problem.PanicLogger)
+		handler = problem.Recover(pl, handler)
+	}
+	if conf.auth != nil {
+		handler = auth.Handler(conf.auth, handler)
+	}
 	if conf.server.AccessLog {
+		// make the authenticated principal and request details available to
+		// the access logger
 		handler = accesslog.NewHandler(handler, conf.accessLogger())
+		handler = auth.AddPrincipal(logger.AddRequestDetails(handler))
 	}
 	if base.telem != nil {
-		handler = otelhttp.NewHandler(handler, conf.name,
+		if len(conf.baggage) != 0 {
+			handler = addBaggageAttributes(conf.baggage, handler)
+		}
+
+		otelOpts := []otelhttp.Option{
 			otelhttp.WithServerName(base.server.Name()),
 			otelhttp.WithMessageEvents(otelhttp.ReadEvents, otelhttp.WriteEvents),
+			otelhttp.WithFilter(base.router.traceFilter),
 			otelhttp.WithMeterProvider(base.telem.MeterProvider()),
 			otelhttp.WithTracerProvider(base.telem.TracerProvider()),
-		)
+		}
+		if base.propagator != nil {
+			otelOpts = append(otelOpts, otelhttp.WithPropagators(base.propagator))
+		}
+		handler = otelhttp.NewHandler(handler, conf.name, otelOpts...)
+		// the route must be known before the request's span is started
+		handler = base.router.resolveRoute(handler)
+	} else if base.propagator != nil {
+		handler = extractPropagation(base.propagator, handler)
 	}
 
 	base.server.Handler = handler
+	base.router.seal()
+	_, base.lifecycle = base.tracer().Start(context.Background(), "webapp.lifecycle",
+		trace.WithTimestamp(base.created),
+	)
 	return base, nil
 }
 
@@ -96,7 +178,18 @@ func (base *Base) BuildInfo() *buildinfo.BuildInfo { return base.build }
 
 func (base *Base) Telemetry() *telemetry.Telemetry { return base.telem }
 
-func (base *Base) HealthChecker() *healthcheck.Checker { return base.health }
+func (base *Base) tracer() trace.Tracer {
+	return base.telem.TracerProvider().Tracer(meterName)
+}
+
+// HealthChecker returns the [MeasuredChecker] created by [WithHealthChecker],
+// or nil when health checking is not enabled.
+func (base *Base) HealthChecker() *MeasuredChecker {
+	if base.health == nil {
+		return nil
+	}
+	return &MeasuredChecker{Checker: base.health, base: base}
+}
 
 func (base *Base) RouteHandler() serv.RouteHandler { return base.router }
 
@@ -113,18 +206,53 @@ func (base *Base) CheckHealth(_ context.Context) healthcheck.Status {
 	}
 }
 
+// Run starts the server using [serv.Server.Run] and blocks until it is shut
+// down. Its startup, until the server is started, is traced.
 func (base *Base) Run(ctx context.Context) error {
-	if ctx != nil {
+	if ctx == nil {
+		ctx = context.Background()
+	} else {
 		base.server.BaseContext = serv.BaseContext(ctx)
 	}
-	return base.server.Run()
+
+	// the span is ended by serverLogger once the server is started
+	_, base.runSpan = base.tracer().Start(ctx, "webapp.Run")
+	err := base.server.Run()
+	if base.runSpan != nil {
+		// the server failed to start
+		endSpan(base.runSpan, err)
+		base.runSpan = nil
+	} else if err != nil && base.lifecycle != nil {
+		base.lifecycle.RecordError(err)
+	}
+	return err
 }
 
+// Shutdown shuts down the server, stops all metrics collection and flushes
+// and shuts down the telemetry. The shutdown of the server is traced.
 func (base *Base) Shutdown(ctx context.Context) error {
+	ctx, span := base.tracer().Start(ctx, "webapp.Base.Shutdown")
+
 	// shutdown server before shutting down other services
+	_, serverSpan := base.tracer().Start(ctx, "webapp.shutdown.server")
 	err := base.server.Shutdown(ctx)
+	endSpan(serverSpan, err)
+
+	for _, reg := range base.callbacks {
+		err = errors.Append(err, reg.Unregister())
+	}
+	// spans must end before the TracerProvider is shut down to be exported
+	endSpan(span, err)
+	if base.lifecycle != nil {
+		base.lifecycle.End()
+	}
+
 	// force flush before shutting down telemetry providers
 	err = errors.Append(err, base.telem.ForceFlush(ctx))
 	err = errors.Append(err, base.telem.Shutdown(ctx))
+	if base.logs != nil {
+		// shutdown flushes all remaining log records
+		err = errors.Append(err, base.logs.Shutdown(ctx))
+	}
 	return err
 }
@@ -0,0 +1,386 @@
+// Copyright (c) 2026, Roel Schut. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package webapp
+
+import (
+	"context"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/go-pogo/errors"
+	"github.com/go-pogo/serv"
+	"github.com/go-pogo/webapp/logger"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+)
+
+const ErrCircuitOpen errors.Msg = "circuit breaker is open"
+
+// RequestIDHeader is the header used to propagate the request id of the
+// incoming request to outbound requests.
+const RequestIDHeader = "X-Request-ID"
+
+// ClientConfig is the configuration used by [Base.HTTPClient] to create a new
+// [http.Client].
+type ClientConfig struct {
+	// Timeout limits the time a single attempt may take. A zero value means no
+	// timeout.
+	Timeout time.Duration `default:"10s"`
+	// HostTimeouts overrides Timeout for the listed hosts. Hosts are listed
+	// by name without port, see [url.URL.Hostname], which is also the key of
+	// the circuit breakers.
+	HostTimeouts map[string]time.Duration
+	// MaxRetries is the max amount of retries of a failed request with an
+	// idempotent method.
+	MaxRetries int `default:"2"`
+	// RetryWaitMin is the minimum time to wait before retrying a request.
+	RetryWaitMin time.Duration `default:"100ms"`
+	// RetryWaitMax is the maximum time to wait before retrying a request. The
+	// Retry-After header of a 429 or 503 response is honoured instead of the
+	// backoff, a response which asks to wait longer than RetryWaitMax is
+	// returned without retrying.
+	RetryWaitMax time.Duration `default:"2s"`
+	// BreakerThreshold is the amount of consecutive failures to a host after
+	// which the circuit breaker opens. A zero value disables the breaker.
+	BreakerThreshold int `default:"5"`
+	// BreakerTimeout is the time the circuit breaker stays open before a new
+	// attempt to the host is allowed.
+	BreakerTimeout time.Duration `default:"30s"`
+	// Transport is the underlying [http.RoundTripper] used to make requests.
+	// It defaults to [http.DefaultTransport] when nil.
+	Transport http.RoundTripper
+}
+
+// HTTPClient returns a new [http.Client] which retries failed requests with an
+// idempotent method, enforces per host timeouts and uses a circuit breaker per
+// host. Requests which are canceled by the caller, or whose deadline is
+// exceeded, do not count as failures of the host. The request id of the
+// incoming request is propagated to outbound requests. When telemetry is
+// configured, all requests are traced and measured using
+// [otelhttp.NewTransport]. The trace context and baggage are injected using
+// the propagator set with [WithPropagation], also when telemetry is not
+// configured. Requests are logged when the [Logger] set with [WithLogger]
+// implements [logger.ClientLogger].
+func (base *Base) HTTPClient(name string, conf ClientConfig) *http.Client {
+	next := conf.Transport
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	if base.telem != nil {
+		otelOpts := []otelhttp.Option{
+			otelhttp.WithMeterProvider(base.telem.MeterProvider()),
+			otelhttp.WithTracerProvider(base.telem.TracerProvider()),
+			otelhttp.WithSpanNameFormatter(func(_ string, req *http.Request) string {
+				return name + " " + req.Method
+			}),
+		}
+		if base.propagator != nil {
+			otelOpts = append(otelOpts, otelhttp.WithPropagators(base.propagator))
+		}
+		next = otelhttp.NewTransport(next, otelOpts...)
+	} else if base.propagator != nil {
+		next = &propagationTransport{prop: base.propagator, next: next}
+	}
+
+	cl, _ := base.log.(logger.ClientLogger)
+	return &http.Client{
+		Transport: &clientTransport{
+			name:     name,
+			conf:     conf,
+			next:     next,
+			log:      cl,
+			breakers: make(map[string]*breaker),
+		},
+	}
+}
+
+var _ http.RoundTripper = (*clientTransport)(nil)
+
+type clientTransport struct {
+	name string
+	conf ClientConfig
+	next http.RoundTripper
+	log  logger.ClientLogger
+
+	mut      sync.Mutex
+	breakers map[string]*breaker
+}
+
+func (t *clientTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if id := serv.RequestID(req.Context()); id != "" && req.Header.Get(RequestIDHeader) == "" {
+		req = req.Clone(req.Context())
+		req.Header.Set(RequestIDHeader, id)
+	}
+
+	host := req.URL.Hostname()
+	brk := t.breaker(host)
+	maxAttempts := 1
+	if t.canRetry(req) {
+		maxAttempts += t.conf.MaxRetries
+	}
+
+	for attempt := 1; ; attempt++ {
+		if !brk.allow() {
+			return nil, errors.New(ErrCircuitOpen)
+		}
+
+		attemptReq := req
+		if attempt > 1 {
+			// the caller's request must not be modified, retries use a clone
+			// with a new body
+			attemptReq = req.Clone(req.Context())
+			if req.GetBody != nil {
+				var err error
+				if attemptReq.Body, err = req.GetBody(); err != nil {
+					return nil, errors.WithStack(err)
+				}
+			}
+		}
+
+		resp, err := t.attempt(attemptReq, host, attempt)
+		if req.Context().Err() != nil {
+			// the caller gave up on the request, which says nothing about
+			// the health of the host
+			brk.abort()
+			return resp, err
+		}
+
+		failed := err != nil || shouldRetryStatus(resp.StatusCode)
+		brk.record(!failed)
+		if !failed || attempt >= maxAttempts {
+			return resp, err
+		}
+
+		wait := t.backoff(attempt)
+		if resp != nil {
+			if d, ok := retryAfter(resp, time.Now()); ok {
+				if t.conf.RetryWaitMax > 0 && d > t.conf.RetryWaitMax {
+					return resp, nil
+				}
+				wait = d
+			}
+			// drain and close body so the connection can be reused
+			_, _ = io.Copy(io.Discard, resp.Body)
+			_ = resp.Body.Close()
+		}
+		if err = sleep(req.Context(), wait); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (t *clientTransport) attempt(req *http.Request, host string, attempt int) (*http.Response, error) {
+	var cancelFn context.CancelFunc
+	if timeout := t.timeout(host); timeout > 0 {
+		var ctx context.Context
+		ctx, cancelFn = context.WithTimeout(req.Context(), timeout)
+		req = req.WithContext(ctx)
+	}
+
+	start := time.Now()
+	resp, err := t.next.RoundTrip(req)
+
+	if t.log != nil {
+		det := logger.ClientDetails{
+			ClientName: t.name,
+			Attempt:    attempt,
+			Duration:   time.Since(start),
+			Err:        err,
+		}
+		if resp != nil {
+			det.StatusCode = resp.StatusCode
+		}
+		t.log.LogClientRequest(req.Context(), det, req)
+	}
+
+	if cancelFn != nil {
+		if err != nil || resp == nil {
+			cancelFn()
+		} else {
+			// the context should remain valid until the body is closed
+			resp.Body = &cancelBody{ReadCloser: resp.Body, cancelFn: cancelFn}
+		}
+	}
+	return resp, err
+}
+
+func (t *clientTransport) timeout(host string) time.Duration {
+	if d, ok := t.conf.HostTimeouts[host]; ok {
+		return d
+	}
+	return t.conf.Timeout
+}
+
+func (t *clientTransport) canRetry(req *http.Request) bool {
+	if t.conf.MaxRetries <= 0 || !isIdempotent(req.Method) {
+		return false
+	}
+	return req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+}
+
+// backoff returns the duration to wait before retrying the attempt.
+func (t *clientTransport) backoff(attempt int) time.Duration {
+	d := t.conf.RetryWaitMin << (attempt - 1)
+	if t.conf.RetryWaitMax > 0 && (d > t.conf.RetryWaitMax || d <= 0) {
+		d = t.conf.RetryWaitMax
+	}
+	if d > 0 {
+		// add up to 25% jitter
+		d += rand.N(d/4 + 1)
+	}
+	return d
+}
+
+// retryAfter returns the duration to wait according to the Retry-After header
+// of a 429 or 503 response, and reports whether it is present and valid.
+func retryAfter(resp *http.Response, now time.Time) (time.Duration, bool) {
+	if resp.StatusCode != http.StatusTooManyRequests && resp.StatusCode != http.StatusServiceUnavailable {
+		return 0, false
+	}
+
+	val := resp.Header.Get("Retry-After")
+	if val == "" {
+		return 0, false
+	}
+	if sec, err := strconv.Atoi(val); err == nil {
+		if sec < 0 {
+			return 0, false
+		}
+		return time.Duration(sec) * time.Second, true
+	}
+	if at, err := http.ParseTime(val); err == nil {
+		return max(at.Sub(now), 0), true
+	}
+	return 0, false
+}
+
+// sleep blocks until d has passed, or the context is canceled.
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+func (t *clientTransport) breaker(host string) *breaker {
+	if t.conf.BreakerThreshold <= 0 {
+		return nil
+	}
+
+	t.mut.Lock()
+	defer t.mut.Unlock()
+
+	brk, ok := t.breakers[host]
+	if !ok {
+		brk = &breaker{
+			threshold: t.conf.BreakerThreshold,
+			timeout:   t.conf.BreakerTimeout,
+		}
+		t.breakers[host] = brk
+	}
+	return brk
+}
+
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace,
+		http.MethodPut, http.MethodDelete:
+		return true
+	default:
+		return false
+	}
+}
+
+func shouldRetryStatus(code int) bool {
+	switch code {
+	case http.StatusTooManyRequests, http.StatusBadGateway,
+		http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	default:
+		return false
+	}
+}
+
+// breaker is a simple circuit breaker. It opens after threshold consecutive
+// failures and allows a single trial request once timeout has elapsed.
+// A nil breaker always allows requests.
+type breaker struct {
+	threshold int
+	timeout   time.Duration
+
+	mut       sync.Mutex
+	failures  int
+	openUntil time.Time
+	trial     bool
+}
+
+func (b *breaker) allow() bool {
+	if b == nil {
+		return true
+	}
+
+	b.mut.Lock()
+	defer b.mut.Unlock()
+
+	if b.failures < b.threshold {
+		return true
+	}
+	if b.trial || time.Now().Before(b.openUntil) {
+		return false
+	}
+	// half-open, allow a single trial request
+	b.trial = true
+	return true
+}
+
+// abort ends a trial request without recording its result.
+func (b *breaker) abort() {
+	if b == nil {
+		return
+	}
+
+	b.mut.Lock()
+	b.trial = false
+	b.mut.Unlock()
+}
+
+func (b *breaker) record(success bool) {
+	if b == nil {
+		return
+	}
+
+	b.mut.Lock()
+	defer b.mut.Unlock()
+
+	b.trial = false
+	if success {
+		b.failures = 0
+		return
+	}
+
+	b.failures++
+	if b.failures >= b.threshold {
+		b.openUntil = time.Now().Add(b.timeout)
+	}
+}
+
+type cancelBody struct {
+	io.ReadCloser
+	cancelFn context.CancelFunc
+}
+
+func (b *cancelBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancelFn()
+	return err
+}
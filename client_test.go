@@ -0,0 +1,217 @@
+// Copyright (c) 2026, Roel Schut. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package webapp
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-pogo/errors"
+	"github.com/go-pogo/serv"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRetryAfter(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	tests := map[string]struct {
+		code   int
+		header string
+		want   time.Duration
+		wantOk bool
+	}{
+		"seconds":      {http.StatusServiceUnavailable, "3", 3 * time.Second, true},
+		"date":         {http.StatusTooManyRequests, now.Add(time.Minute).Format(http.TimeFormat), time.Minute, true},
+		"past date":    {http.StatusTooManyRequests, now.Add(-time.Minute).Format(http.TimeFormat), 0, true},
+		"invalid":      {http.StatusServiceUnavailable, "soon", 0, false},
+		"negative":     {http.StatusServiceUnavailable, "-1", 0, false},
+		"missing":      {http.StatusServiceUnavailable, "", 0, false},
+		"other status": {http.StatusBadGateway, "3", 0, false},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			resp := &http.Response{StatusCode: tc.code, Header: make(http.Header)}
+			if tc.header != "" {
+				resp.Header.Set("Retry-After", tc.header)
+			}
+
+			have, ok := retryAfter(resp, now)
+			assert.Equal(t, tc.wantOk, ok)
+			assert.Equal(t, tc.want, have)
+		})
+	}
+}
+
+func TestBase_HTTPClient(t *testing.T) {
+	base, err := New()
+	require.NoError(t, err)
+
+	t.Run("retry", func(t *testing.T) {
+		var calls int32
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			if atomic.AddInt32(&calls, 1) < 3 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer srv.Close()
+
+		client := base.HTTPClient("test", ClientConfig{MaxRetries: 2})
+		resp, err := client.Get(srv.URL)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, int32(3), atomic.LoadInt32(&calls))
+	})
+	t.Run("retry with body", func(t *testing.T) {
+		var bodies []string
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			body, _ := io.ReadAll(req.Body)
+			bodies = append(bodies, string(body))
+			if len(bodies) < 2 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+			}
+		}))
+		defer srv.Close()
+
+		req, err := http.NewRequest(http.MethodPut, srv.URL, strings.NewReader("body"))
+		require.NoError(t, err)
+		reqBody := req.Body
+
+		client := base.HTTPClient("test", ClientConfig{MaxRetries: 2})
+		resp, err := client.Transport.RoundTrip(req)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, []string{"body", "body"}, bodies)
+		assert.True(t, reqBody == req.Body, "caller's request is not modified")
+	})
+	t.Run("no retry non-idempotent", func(t *testing.T) {
+		var calls int32
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			atomic.AddInt32(&calls, 1)
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer srv.Close()
+
+		client := base.HTTPClient("test", ClientConfig{MaxRetries: 2})
+		resp, err := client.Post(srv.URL, "text/plain", nil)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+		assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+	})
+	t.Run("circuit breaker", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusBadGateway)
+		}))
+		defer srv.Close()
+
+		client := base.HTTPClient("test", ClientConfig{
+			BreakerThreshold: 2,
+			BreakerTimeout:   time.Minute,
+		})
+		for i := 0; i < 2; i++ {
+			_, err = client.Get(srv.URL)
+			require.NoError(t, err)
+		}
+
+		_, err = client.Get(srv.URL)
+		assert.ErrorIs(t, err, ErrCircuitOpen)
+	})
+	t.Run("circuit breaker ignores canceled requests", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, req *http.Request) {
+			<-req.Context().Done()
+		}))
+		defer srv.Close()
+
+		client := base.HTTPClient("test", ClientConfig{
+			BreakerThreshold: 1,
+			BreakerTimeout:   time.Minute,
+		})
+		for i := 0; i < 2; i++ {
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+			req, err := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL, nil)
+			require.NoError(t, err)
+
+			_, err = client.Do(req)
+			cancel()
+			assert.ErrorIs(t, err, context.DeadlineExceeded)
+		}
+	})
+	t.Run("host key", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, req *http.Request) {
+			<-req.Context().Done()
+		}))
+		defer srv.Close()
+
+		client := base.HTTPClient("test", ClientConfig{
+			Timeout:          time.Minute,
+			HostTimeouts:     map[string]time.Duration{"127.0.0.1": 10 * time.Millisecond},
+			BreakerThreshold: 1,
+			BreakerTimeout:   time.Minute,
+		})
+		_, err = client.Get(srv.URL)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+		tr := client.Transport.(*clientTransport)
+		assert.Contains(t, tr.breakers, "127.0.0.1")
+	})
+	t.Run("retry after", func(t *testing.T) {
+		var calls int32
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			if atomic.AddInt32(&calls, 1) == 1 {
+				w.Header().Set("Retry-After", "1")
+				w.WriteHeader(http.StatusTooManyRequests)
+			}
+		}))
+		defer srv.Close()
+
+		client := base.HTTPClient("test", ClientConfig{MaxRetries: 1, RetryWaitMax: 2 * time.Second})
+		start := time.Now()
+		resp, err := client.Get(srv.URL)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.GreaterOrEqual(t, time.Since(start), time.Second)
+		assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+
+		t.Run("exceeds max wait", func(t *testing.T) {
+			atomic.StoreInt32(&calls, 0)
+			client := base.HTTPClient("test", ClientConfig{MaxRetries: 1, RetryWaitMax: 10 * time.Millisecond})
+			resp, err := client.Get(srv.URL)
+			require.NoError(t, err)
+			assert.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+			assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+		})
+	})
+	t.Run("request id", func(t *testing.T) {
+		const want = "some-request-id"
+		srv := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, req *http.Request) {
+			assert.Equal(t, want, req.Header.Get(RequestIDHeader))
+		}))
+		defer srv.Close()
+
+		ctx := serv.ContextWithInfo(context.Background(), serv.Info{RequestID: want})
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL, nil)
+		require.NoError(t, err)
+
+		_, err = base.HTTPClient("test", ClientConfig{}).Do(req)
+		assert.NoError(t, err)
+	})
+	t.Run("timeout", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, req *http.Request) {
+			<-req.Context().Done()
+		}))
+		defer srv.Close()
+
+		client := base.HTTPClient("test", ClientConfig{Timeout: 10 * time.Millisecond})
+		_, err = client.Get(srv.URL)
+		assert.True(t, errors.Is(err, context.DeadlineExceeded))
+	})
+}
@@ -0,0 +1,214 @@
+// Copyright (c) 2026, Roel Schut. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package webapp
+
+import (
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/go-pogo/serv"
+	"github.com/go-pogo/serv/accesslog"
+	"github.com/go-pogo/webapp/auth"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	semconv "go.opentelemetry.io/otel/semconv/v1.40.0"
+)
+
+// DeprecatedCallsMetricName is the name of the counter metric which counts
+// calls to deprecated routes.
+const DeprecatedCallsMetricName = "webapp.route.deprecated.calls"
+
+// DeprecatedRouteClientKey is the attribute key of the client which called a
+// deprecated route. Its value is the subject of the authenticated principal,
+// or [OtherDeprecatedRouteClient].
+const DeprecatedRouteClientKey = attribute.Key("webapp.route.client")
+
+// OtherDeprecatedRouteClient is the value of the [DeprecatedRouteClientKey]
+// attribute of unauthenticated clients, and of authenticated clients once
+// the max amount of distinct clients per route is reached.
+const OtherDeprecatedRouteClient = "other"
+
+const (
+	// maxDeprecatedClientAttrs is the max amount of distinct clients per
+	// deprecated route which are used as attribute of the calls counter.
+	maxDeprecatedClientAttrs = 100
+	// maxDeprecatedClientLogs is the max amount of clients per deprecated
+	// route which are remembered to log their first call only once.
+	maxDeprecatedClientLogs = 1024
+	// deprecatedClientLogTTL is the duration after which a client's call is
+	// logged again.
+	deprecatedClientLogTTL = 24 * time.Hour
+)
+
+// RouteDeprecation marks a route as deprecated.
+type RouteDeprecation struct {
+	// Since is the moment the route is deprecated. It is sent as "Deprecation"
+	// header, as described in RFC 9745. When zero, the header is omitted.
+	Since time.Time
+	// Sunset is the moment the route is expected to become unavailable. It is
+	// sent as "Sunset" header, as described in RFC 8594.
+	Sunset time.Time
+	// Successor is the URL of the route which replaces the deprecated route.
+	// It is sent as "Link" header with relation type "successor-version".
+	Successor string
+}
+
+// deprecateRoute wraps handler so responses contain the route's deprecation
+// headers. Calls are counted per route and authenticated client, and the
+// first call of each client within [deprecatedClientLogTTL] is logged when the
+// [Logger] implements [logger.DeprecatedRouteLogger].
+func (mux *router) deprecateRoute(route serv.Route, dep RouteDeprecation, handler http.Handler) http.Handler {
+	var deprecation string
+	if !dep.Since.IsZero() {
+		deprecation = "@" + strconv.FormatInt(dep.Since.Unix(), 10)
+	}
+
+	var sunset string
+	if !dep.Sunset.IsZero() {
+		sunset = dep.Sunset.UTC().Format(http.TimeFormat)
+	}
+
+	var link string
+	if dep.Successor != "" {
+		link = "<" + dep.Successor + `>; rel="successor-version"`
+	}
+
+	attrs := &clientAttrs{clients: make(map[string]struct{})}
+	logged := &recentClients{clients: make(map[string]time.Time)}
+	routeAttr := semconv.HTTPRoute(routePath(route.Pattern))
+	deprecated := http.HandlerFunc(func(wri http.ResponseWriter, req *http.Request) {
+		header := wri.Header()
+		if deprecation != "" {
+			header.Set("Deprecation", deprecation)
+		}
+		if sunset != "" {
+			header.Set("Sunset", sunset)
+		}
+		if link != "" {
+			header.Add("Link", link)
+		}
+
+		// the call is counted once the principal is known, also when
+		// handler panics
+		defer func() {
+			ctx := req.Context()
+			subject, authenticated := principalSubject(req)
+			mux.deprecatedCalls().Add(ctx, 1, metric.WithAttributes(
+				routeAttr,
+				DeprecatedRouteClientKey.String(attrs.value(subject, authenticated)),
+			))
+
+			if mux.depLog == nil {
+				return
+			}
+			client := subject
+			if !authenticated {
+				client = accesslog.RemoteAddr(req)
+			}
+			if logged.add(client, time.Now()) {
+				mux.depLog.LogDeprecatedRoute(ctx, route, client)
+			}
+		}()
+
+		handler.ServeHTTP(wri, req)
+	})
+	// make the principal of an authenticator further down the chain available
+	return auth.AddPrincipal(deprecated)
+}
+
+// deprecatedCalls returns the counter of calls to deprecated routes. It is
+// created using the router's [metric.MeterProvider] on first use.
+func (mux *router) deprecatedCalls() metric.Int64Counter {
+	mux.depCallsOnce.Do(func() {
+		mp := mux.meterProvider
+		if mp == nil {
+			mp = noopMeterProvider
+		}
+		mux.depCalls, _ = mp.Meter(meterName).Int64Counter(DeprecatedCallsMetricName,
+			metric.WithDescription("Number of calls to deprecated routes."),
+			metric.WithUnit("{call}"),
+		)
+	})
+	return mux.depCalls
+}
+
+// principalSubject returns the subject of the authenticated principal of
+// req, and reports whether req is authenticated by a principal with a
+// subject, which identifies the client.
+func principalSubject(req *http.Request) (string, bool) {
+	if p := auth.PrincipalFromContext(req.Context()); p != nil && p.Subject != "" {
+		return p.Subject, true
+	}
+	return "", false
+}
+
+// clientAttrs limits the amount of distinct values of the
+// [DeprecatedRouteClientKey] attribute, so the amount of series of the calls
+// counter is bounded.
+type clientAttrs struct {
+	mut     sync.Mutex
+	clients map[string]struct{}
+}
+
+func (ca *clientAttrs) value(subject string, authenticated bool) string {
+	if !authenticated {
+		return OtherDeprecatedRouteClient
+	}
+
+	ca.mut.Lock()
+	defer ca.mut.Unlock()
+	if _, ok := ca.clients[subject]; ok {
+		return subject
+	}
+	if len(ca.clients) >= maxDeprecatedClientAttrs {
+		return OtherDeprecatedRouteClient
+	}
+	ca.clients[subject] = struct{}{}
+	return subject
+}
+
+// recentClients remembers at most [maxDeprecatedClientLogs] clients for
+// [deprecatedClientLogTTL]. When full, the least recently added client is
+// forgotten.
+type recentClients struct {
+	mut     sync.Mutex
+	clients map[string]time.Time
+}
+
+// add adds client and reports whether it was not already remembered.
+func (rc *recentClients) add(client string, now time.Time) bool {
+	rc.mut.Lock()
+	defer rc.mut.Unlock()
+
+	if added, ok := rc.clients[client]; ok && now.Sub(added) < deprecatedClientLogTTL {
+		return false
+	}
+	if len(rc.clients) >= maxDeprecatedClientLogs {
+		rc.evict(now)
+	}
+	rc.clients[client] = now
+	return true
+}
+
+// evict removes all expired clients, or the oldest client when none are
+// expired.
+func (rc *recentClients) evict(now time.Time) {
+	var oldest string
+	var oldestAdded time.Time
+	for client, added := range rc.clients {
+		if now.Sub(added) >= deprecatedClientLogTTL {
+			delete(rc.clients, client)
+			continue
+		}
+		if oldestAdded.IsZero() || added.Before(oldestAdded) {
+			oldest, oldestAdded = client, added
+		}
+	}
+	if len(rc.clients) >= maxDeprecatedClientLogs {
+		delete(rc.clients, oldest)
+	}
+}
@@ -0,0 +1,139 @@
+// Copyright (c) 2026, Roel Schut. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package webapp
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/go-pogo/serv"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	semconv "go.opentelemetry.io/otel/semconv/v1.40.0"
+)
+
+type deprecationLogger struct {
+	Logger
+	clients []string
+}
+
+func (l *deprecationLogger) LogRegisterRoute(serv.Route) {}
+
+func (l *deprecationLogger) LogDeprecatedRoute(_ context.Context, _ serv.Route, client string) {
+	l.clients = append(l.clients, client)
+}
+
+func TestRouteDeprecation(t *testing.T) {
+	since := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	sunset := time.Date(2026, 7, 1, 0, 0, 0, 0, time.UTC)
+
+	var log deprecationLogger
+	reader := sdkmetric.NewManualReader()
+	base, err := New(
+		WithLogger(&log),
+		func(base *Base, _ *config) error {
+			base.router.meterProvider = sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+			return nil
+		},
+		WithRoutesRegisterer(serv.RoutesRegistererFunc(func(rh serv.RouteHandler) {
+			rh.HandleRoute(WithRouteMeta(serv.Route{
+				Name:    "v1.items",
+				Method:  http.MethodGet,
+				Pattern: "/v1/items",
+				Handler: http.NotFoundHandler(),
+			}, RouteMeta{Deprecation: &RouteDeprecation{
+				Since:     since,
+				Sunset:    sunset,
+				Successor: "/v2/items",
+			}}))
+			rh.HandleRoute(WithRouteMeta(serv.Route{
+				Name:    "v1.panics",
+				Method:  http.MethodGet,
+				Pattern: "/v1/panics",
+				Handler: http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+					panic("oops")
+				}),
+			}, RouteMeta{Deprecation: &RouteDeprecation{Sunset: sunset}}))
+		})),
+	)
+	require.NoError(t, err)
+
+	for _, addr := range []string{"10.0.0.1:1234", "10.0.0.2:1234", "10.0.0.1:5678"} {
+		req := httptest.NewRequest(http.MethodGet, "/v1/items", nil)
+		req.RemoteAddr = addr
+
+		rec := httptest.NewRecorder()
+		base.Server().Handler.ServeHTTP(rec, req)
+		assert.Equal(t, "@1767225600", rec.Header().Get("Deprecation"))
+		assert.Equal(t, "Wed, 01 Jul 2026 00:00:00 GMT", rec.Header().Get("Sunset"))
+		assert.Equal(t, `</v2/items>; rel="successor-version"`, rec.Header().Get("Link"))
+	}
+
+	assert.Equal(t, []string{"10.0.0.1", "10.0.0.2"}, log.clients)
+
+	t.Run("without since", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		assert.Panics(t, func() {
+			base.Server().Handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/panics", nil))
+		})
+		assert.Empty(t, rec.Header().Values("Deprecation"))
+		assert.Equal(t, "Wed, 01 Jul 2026 00:00:00 GMT", rec.Header().Get("Sunset"))
+	})
+
+	var rm metricdata.ResourceMetrics
+	require.NoError(t, reader.Collect(context.Background(), &rm))
+	require.Len(t, rm.ScopeMetrics, 1)
+	require.Len(t, rm.ScopeMetrics[0].Metrics, 1)
+
+	m := rm.ScopeMetrics[0].Metrics[0]
+	assert.Equal(t, DeprecatedCallsMetricName, m.Name)
+
+	calls := make(map[string]int64)
+	for _, dp := range m.Data.(metricdata.Sum[int64]).DataPoints {
+		client, _ := dp.Attributes.Value(DeprecatedRouteClientKey)
+		assert.Equal(t, OtherDeprecatedRouteClient, client.AsString(), "unauthenticated clients share a single series")
+
+		route, _ := dp.Attributes.Value(semconv.HTTPRouteKey)
+		calls[route.AsString()] = dp.Value
+	}
+	assert.Equal(t, map[string]int64{
+		"/v1/items":  3,
+		"/v1/panics": 1,
+	}, calls, "panicking calls are counted")
+}
+
+func TestClientAttrs(t *testing.T) {
+	attrs := &clientAttrs{clients: make(map[string]struct{})}
+	assert.Equal(t, OtherDeprecatedRouteClient, attrs.value("", false))
+	for i := 0; i < maxDeprecatedClientAttrs; i++ {
+		assert.Equal(t, strconv.Itoa(i), attrs.value(strconv.Itoa(i), true))
+	}
+	assert.Equal(t, OtherDeprecatedRouteClient, attrs.value("new", true))
+	assert.Equal(t, "0", attrs.value("0", true))
+}
+
+func TestRecentClients(t *testing.T) {
+	now := time.Now()
+	rc := &recentClients{clients: make(map[string]time.Time)}
+	assert.True(t, rc.add("a", now))
+	assert.False(t, rc.add("a", now.Add(time.Minute)))
+	assert.True(t, rc.add("a", now.Add(deprecatedClientLogTTL)), "expired")
+
+	t.Run("bounded", func(t *testing.T) {
+		rc := &recentClients{clients: make(map[string]time.Time)}
+		for i := 0; i < maxDeprecatedClientLogs; i++ {
+			rc.add(strconv.Itoa(i), now.Add(time.Duration(i)))
+		}
+		assert.True(t, rc.add("new", now.Add(time.Hour)))
+		assert.Len(t, rc.clients, maxDeprecatedClientLogs)
+		assert.NotContains(t, rc.clients, "0", "oldest is evicted")
+	})
+}
@@ -12,6 +12,8 @@ import (
 	"github.com/go-pogo/errors"
 	"github.com/go-pogo/webapp/ctxgroup"
 	"github.com/go-pogo/webapp/rungroup"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
 )
 
 const (
@@ -39,12 +41,34 @@ func Run(ctx context.Context, targets ...func(ctx context.Context) error) error
 
 // Shutdown calls all targets and blocks until all are called and have returned.
 // Returned errors from these functions are collected and returned at the end.
+// The shutdown and each of its targets are traced using the global
+// TracerProvider, use [Base.ShutdownTargets] to trace them using the
+// TracerProvider of [Base] instead. Note that spans which end after the
+// TracerProvider is shut down, e.g. by [Base.Shutdown], are not exported.
 func Shutdown(ctx context.Context, targets ...func(ctx context.Context) error) error {
+	return shutdown(ctx, otel.GetTracerProvider(), targets)
+}
+
+// ShutdownTargets calls all targets, like [Shutdown], but traces the shutdown
+// and each of its targets using the TracerProvider of base. Unlike [Shutdown],
+// it does not use the global TracerProvider, which makes it suitable for use
+// with [WithIsolatedTelemetry].
+func (base *Base) ShutdownTargets(ctx context.Context, targets ...func(ctx context.Context) error) error {
+	return shutdown(ctx, base.telem.TracerProvider(), targets)
+}
+
+func shutdown(ctx context.Context, tp trace.TracerProvider, targets []func(ctx context.Context) error) error {
+	tracer := tp.Tracer(meterName)
+	ctx, span := tracer.Start(ctx, "webapp.Shutdown")
+
 	grp := ctxgroup.New(ctx)
 	for i := range targets {
-		grp.Go(targets[i])
+		grp.Go(traceShutdownTarget(tracer, targets[i]))
 	}
-	return errors.Wrap(grp.Wait(), ErrDuringShutdown)
+
+	err := errors.Wrap(grp.Wait(), ErrDuringShutdown)
+	endSpan(span, err)
+	return err
 }
 
 // ShutdownTimeout calls all targets and blocks until all are called and have
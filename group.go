@@ -0,0 +1,187 @@
+// Copyright (c) 2026, Roel Schut. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package webapp
+
+import (
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/go-pogo/serv"
+	"github.com/go-pogo/serv/middleware"
+	"github.com/go-pogo/webapp/auth"
+)
+
+// GroupOption configures a [Group].
+type GroupOption func(grp *Group)
+
+// WithGroupName sets the namespace of the [Group]'s route names. Names of
+// routes handled by the [Group] are prefixed with the namespace and a dot.
+func WithGroupName(name string) GroupOption {
+	return func(grp *Group) {
+		grp.name = joinName(grp.name, name)
+	}
+}
+
+// WithGroupMiddleware wraps the handlers of all routes handled by the [Group]
+// with the provided middleware.
+func WithGroupMiddleware(wrap ...middleware.Wrapper) GroupOption {
+	return func(grp *Group) {
+		grp.middleware = append(grp.middleware, wrap...)
+	}
+}
+
+// WithGroupAuthenticator authenticates all requests to the routes handled by
+// the [Group] using the provided [auth.Authenticator].
+func WithGroupAuthenticator(a auth.Authenticator) GroupOption {
+	return WithGroupMiddleware(auth.Middleware(a))
+}
+
+// WithHostNotFoundHandler sets the handler which is used for requests to the
+// host of a [Group] created with [Base.Host] which do not match any route.
+func WithHostNotFoundHandler(h http.Handler) GroupOption {
+	return func(grp *Group) {
+		if grp.host != "" {
+			grp.router.withHostNotFoundHandler(grp.host, h)
+		}
+	}
+}
+
+var _ serv.Router = (*Group)(nil)
+
+// Group is a [serv.Router] which registers routes that share a pattern prefix,
+// middleware and route name namespace. Groups created with [Base.Host] only
+// match requests to their host.
+type Group struct {
+	router     *router
+	host       string
+	prefix     string
+	name       string
+	middleware middleware.Middleware
+}
+
+// Group returns a new [Group] which registers its routes on the [Base]'s
+// router. The patterns of all routes are prefixed with prefix, which is
+// joined to the patterns' paths like [path.Join] does. A leading "/" is added
+// to prefix when it is missing.
+func (base *Base) Group(prefix string, opts ...GroupOption) *Group {
+	return newGroup(base.router, "", joinPrefix("", prefix), "", nil, opts)
+}
+
+// Host returns a new [Group] which registers its routes on the [Base]'s router
+// with host as pattern host, e.g. "example.com". Requests to a different host
+// do not match the [Group]'s routes. Use [WithHostNotFoundHandler] to set a
+// host specific not found handler, and [WithHostCertificate] to serve a host
+// specific TLS certificate.
+func (base *Base) Host(host string, opts ...GroupOption) *Group {
+	return newGroup(base.router, strings.ToLower(host), "", "", nil, opts)
+}
+
+func newGroup(r *router, host, prefix, name string, mw middleware.Middleware, opts []GroupOption) *Group {
+	grp := &Group{
+		router: r,
+		host:   host,
+		prefix: prefix,
+		name:   name,
+		// copy to prevent subgroups from modifying their parent's middleware
+		middleware: append(middleware.Middleware(nil), mw...),
+	}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(grp)
+		}
+	}
+	return grp
+}
+
+// Group returns a new subgroup which inherits the host, prefix, middleware and
+// name namespace of its parent. Its prefix is joined to the parent's prefix,
+// e.g. "/api" and "v1" result in "/api/v1".
+func (grp *Group) Group(prefix string, opts ...GroupOption) *Group {
+	return newGroup(grp.router, grp.host, joinPrefix(grp.prefix, prefix), grp.name, grp.middleware, opts)
+}
+
+// Host returns the host of the [Group], if any.
+func (grp *Group) Host() string { return grp.host }
+
+// Prefix returns the pattern prefix of the [Group].
+func (grp *Group) Prefix() string { return grp.prefix }
+
+// Name returns the route name namespace of the [Group].
+func (grp *Group) Name() string { return grp.name }
+
+func (grp *Group) Handle(pattern string, handler http.Handler) {
+	method, pattern := splitPattern(pattern)
+	grp.HandleRoute(serv.Route{
+		Method:  method,
+		Pattern: pattern,
+		Handler: handler,
+	})
+}
+
+func (grp *Group) HandleFunc(pattern string, handler func(http.ResponseWriter, *http.Request)) {
+	grp.Handle(pattern, http.HandlerFunc(handler))
+}
+
+// HandleRoute registers the route with its fully qualified name and pattern,
+// and its handler wrapped with the [Group]'s middleware.
+func (grp *Group) HandleRoute(route serv.Route) {
+	route.Pattern = joinPattern(grp.prefix, route.Pattern)
+	if grp.host != "" && strings.HasPrefix(route.Pattern, "/") {
+		route.Pattern = grp.host + route.Pattern
+	}
+	if route.Name != "" {
+		route.Name = joinName(grp.name, route.Name)
+	}
+	if len(grp.middleware) != 0 {
+		route = wrapRouteHandler(route, grp.middleware.Wrap)
+	}
+	grp.router.HandleRoute(route)
+}
+
+// ServeHTTP is part of the [http.Handler] interface. It serves requests using
+// the [Base]'s router.
+func (grp *Group) ServeHTTP(wri http.ResponseWriter, req *http.Request) {
+	grp.router.ServeHTTP(wri, req)
+}
+
+// joinPrefix joins prefix to parent like [path.Join] does. The result is
+// either empty or starts with a "/" and has no trailing "/".
+func joinPrefix(parent, prefix string) string {
+	if p := path.Join("/", parent, prefix); p != "/" {
+		return p
+	}
+	return ""
+}
+
+// joinPattern adds prefix to the path of pattern, which may start with a
+// host. The path of a pattern which consists of only a host is "/". A
+// trailing "/" of the path is kept.
+func joinPattern(prefix, pattern string) string {
+	if prefix == "" {
+		return pattern
+	}
+
+	host, p := pattern, "/"
+	if i := strings.IndexByte(pattern, '/'); i >= 0 {
+		host, p = pattern[:i], pattern[i:]
+	}
+
+	joined := path.Join(prefix, p)
+	if strings.HasSuffix(p, "/") {
+		joined += "/"
+	}
+	return host + joined
+}
+
+func joinName(namespace, name string) string {
+	if namespace == "" {
+		return name
+	}
+	if name == "" {
+		return namespace
+	}
+	return namespace + "." + name
+}
@@ -0,0 +1,153 @@
+// Copyright (c) 2026, Roel Schut. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package webapp
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-pogo/easytls"
+	"github.com/go-pogo/serv"
+	"github.com/go-pogo/webapp/auth"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type routesLogger struct {
+	Logger
+	routes []serv.Route
+}
+
+func (l *routesLogger) LogRegisterRoute(route serv.Route) {
+	l.routes = append(l.routes, route)
+}
+
+func TestBase_Group(t *testing.T) {
+	var log routesLogger
+	base, err := New(WithLogger(&log))
+	require.NoError(t, err)
+
+	api := base.Group("/api/", WithGroupName("api"),
+		WithGroupAuthenticator(&auth.Bearer{Tokens: map[string]string{"token": "user"}}),
+	)
+	v1 := api.Group("/v1", WithGroupName("v1"))
+	v1.HandleRoute(serv.Route{
+		Name:    "users",
+		Method:  http.MethodGet,
+		Pattern: "/users/{id}",
+		Handler: http.HandlerFunc(func(wri http.ResponseWriter, req *http.Request) {
+			_, _ = wri.Write([]byte(req.PathValue("id")))
+		}),
+	})
+
+	require.Len(t, log.routes, 1)
+	assert.Equal(t, "api.v1.users", log.routes[0].Name)
+	assert.Equal(t, "/api/v1/users/{id}", log.routes[0].Pattern)
+
+	t.Run("unauthorized", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		base.RouteHandler().(serv.Router).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/users/123", nil))
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	})
+	t.Run("authorized", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/users/123", nil)
+		req.Header.Set("Authorization", "Bearer token")
+
+		rec := httptest.NewRecorder()
+		v1.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "123", rec.Body.String())
+	})
+}
+
+func TestJoinPattern(t *testing.T) {
+	tests := map[string][3]string{
+		"no prefix": {"", "/foo", "/foo"},
+		"prefix":    {"/api", "/foo", "/api/foo"},
+		"host":      {"/api", "example.com/foo", "example.com/api/foo"},
+		"root":      {"/api", "/", "/api/"},
+		"host only": {"/api", "example.com", "example.com/api/"},
+		"wildcard":  {"/api", "/files/{path...}", "/api/files/{path...}"},
+		"exact":     {"/api", "/{$}", "/api/{$}"},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc[2], joinPattern(tc[0], tc[1]))
+		})
+	}
+}
+
+func TestBase_Host(t *testing.T) {
+	ok := func(body string) http.HandlerFunc {
+		return func(wri http.ResponseWriter, _ *http.Request) { _, _ = wri.Write([]byte(body)) }
+	}
+
+	base, err := New(
+		WithNotFoundHandler(ok("default not found")),
+		WithRoutesRegisterer(serv.RoutesRegistererFunc(func(rh serv.RouteHandler) {
+			rh.HandleRoute(serv.Route{Name: "index", Pattern: "/{$}", Handler: ok("index")})
+		})),
+		WithHost("Example.com", serv.RoutesRegistererFunc(func(rh serv.RouteHandler) {
+			rh.HandleRoute(serv.Route{Name: "index", Pattern: "/{$}", Handler: ok("example index")})
+		}), WithGroupName("example"), WithHostNotFoundHandler(ok("example not found"))),
+	)
+	require.NoError(t, err)
+	assert.Equal(t, "example.com/{$}", base.Routes()[1].Pattern)
+	assert.Equal(t, "example.index", base.Routes()[1].Name)
+
+	tests := map[string]string{
+		"http://localhost/":          "index",
+		"http://localhost/foo":       "default not found",
+		"http://example.com/":        "example index",
+		"http://example.com:8080/":   "example index",
+		"http://example.com/foo":     "example not found",
+		"http://sub.example.com/foo": "default not found",
+	}
+	for target, want := range tests {
+		t.Run(target, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			base.Server().Handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+			assert.Equal(t, want, rec.Body.String())
+		})
+	}
+}
+
+func TestHostCertificates_ApplyTo(t *testing.T) {
+	cert := func(name string) easytls.TLSCertificateLoader {
+		return easytls.TLSCertificateLoaderFunc(func() (*tls.Certificate, error) {
+			return &tls.Certificate{OCSPStaple: []byte(name)}, nil
+		})
+	}
+
+	conf := tls.Config{GetCertificate: easytls.GetCertificate(cert("default"))}
+	require.NoError(t, hostCertificates{"example.com": cert("example")}.ApplyTo(&conf, easytls.TargetServer))
+
+	for name, want := range map[string]string{
+		"Example.com": "example",
+		"other.com":   "default",
+	} {
+		have, err := conf.GetCertificate(&tls.ClientHelloInfo{ServerName: name})
+		require.NoError(t, err)
+		assert.Equal(t, want, string(have.OCSPStaple))
+	}
+}
+
+func TestJoinPrefix(t *testing.T) {
+	tests := map[string][3]string{
+		"empty":          {"", "", ""},
+		"root":           {"", "/", ""},
+		"missing slash":  {"", "api", "/api"},
+		"trailing slash": {"", "/api/", "/api"},
+		"nested":         {"/api", "v1", "/api/v1"},
+		"nested slashes": {"/api", "/v1/", "/api/v1"},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc[2], joinPrefix(tc[0], tc[1]))
+		})
+	}
+}
@@ -0,0 +1,121 @@
+// Copyright (c) 2026, Roel Schut. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package webapp
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/go-pogo/errors"
+	"github.com/go-pogo/webapp/logger"
+	"github.com/go-pogo/webapp/problem"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	ErrorClassClient   = "client_error"
+	ErrorClassServer   = "server_error"
+	ErrorClassTimeout  = "timeout"
+	ErrorClassCanceled = "canceled"
+)
+
+var _ http.Handler = (HandlerFunc)(nil)
+
+// HandlerFunc is a http handler function which returns an error. Returned
+// errors are handled by [HandleError].
+type HandlerFunc func(wri http.ResponseWriter, req *http.Request) error
+
+// ServeHTTP is part of the [http.Handler] interface.
+func (fn HandlerFunc) ServeHTTP(wri http.ResponseWriter, req *http.Request) {
+	if err := fn(wri, req); err != nil {
+		HandleError(wri, req, err)
+	}
+}
+
+// ErrorRenderer renders an error as response.
+type ErrorRenderer interface {
+	RenderError(wri http.ResponseWriter, req *http.Request, err error)
+}
+
+// ErrorRendererFunc is a function that implements [ErrorRenderer].
+type ErrorRendererFunc func(wri http.ResponseWriter, req *http.Request, err error)
+
+func (fn ErrorRendererFunc) RenderError(wri http.ResponseWriter, req *http.Request, err error) {
+	fn(wri, req, err)
+}
+
+// ProblemRenderer returns an [ErrorRenderer] which renders errors as problem
+// details using [problem.DefaultMapper]. It is the default [ErrorRenderer].
+func ProblemRenderer() ErrorRenderer {
+	return ErrorRendererFunc(func(wri http.ResponseWriter, req *http.Request, err error) {
+		_ = problem.WriteError(wri, req, err)
+	})
+}
+
+// ErrorStatusCode returns the status code err is rendered with by
+// [ProblemRenderer].
+func ErrorStatusCode(err error) int { return problem.DefaultMapper.Details(err).Status }
+
+// ClassifyError returns the class of err, which is one of [ErrorClassClient],
+// [ErrorClassServer], [ErrorClassTimeout] or [ErrorClassCanceled].
+func ClassifyError(err error) string {
+	switch {
+	case errors.Is(err, context.DeadlineExceeded), errors.Is(err, http.ErrHandlerTimeout):
+		return ErrorClassTimeout
+	case errors.Is(err, context.Canceled):
+		return ErrorClassCanceled
+	case ErrorStatusCode(err) < http.StatusInternalServerError:
+		return ErrorClassClient
+	default:
+		return ErrorClassServer
+	}
+}
+
+type errorHandling struct {
+	renderer ErrorRenderer
+	log      logger.HandlerErrorLogger
+}
+
+type ctxErrorHandlingKey struct{}
+
+func addErrorHandling(eh errorHandling, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(wri http.ResponseWriter, req *http.Request) {
+		next.ServeHTTP(wri, req.WithContext(
+			context.WithValue(req.Context(), ctxErrorHandlingKey{}, eh),
+		))
+	})
+}
+
+// HandleError records err on the request's span, adds it to the request's
+// [logger.RequestDetails] for the access log and logs server errors using
+// the [Logger] set with [WithLogger], when it implements
+// [logger.HandlerErrorLogger]. It then renders err using the
+// [ErrorRenderer] set with [WithErrorRenderer], or [ProblemRenderer] when
+// none is set.
+func HandleError(wri http.ResponseWriter, req *http.Request, err error) {
+	ctx := req.Context()
+	eh, _ := ctx.Value(ctxErrorHandlingKey{}).(errorHandling)
+	class := ClassifyError(err)
+
+	span := trace.SpanFromContext(ctx)
+	span.RecordError(err)
+	if class != ErrorClassClient {
+		span.SetStatus(codes.Error, err.Error())
+	}
+
+	if rd := logger.RequestDetailsFromContext(ctx); rd != nil {
+		rd.Err = err
+		rd.ErrorClass = class
+	}
+	if eh.log != nil && class == ErrorClassServer {
+		eh.log.LogHandlerError(ctx, err, req)
+	}
+
+	if eh.renderer == nil {
+		eh.renderer = ProblemRenderer()
+	}
+	eh.renderer.RenderError(wri, req, err)
+}
@@ -0,0 +1,69 @@
+// Copyright (c) 2026, Roel Schut. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package webapp
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-pogo/errors"
+	"github.com/go-pogo/serv"
+	"github.com/go-pogo/webapp/logger"
+	"github.com/go-pogo/webapp/problem"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandlerFunc(t *testing.T) {
+	t.Run("default renderer", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		HandlerFunc(func(http.ResponseWriter, *http.Request) error {
+			return errors.WithStatusCode(errors.New("bad"), http.StatusBadRequest)
+		}).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Equal(t, problem.ContentType, rec.Header().Get("Content-Type"))
+	})
+	t.Run("custom renderer", func(t *testing.T) {
+		base, err := New(
+			WithErrorRenderer(ErrorRendererFunc(func(wri http.ResponseWriter, _ *http.Request, err error) {
+				wri.WriteHeader(http.StatusTeapot)
+			})),
+			WithRoutesRegisterer(serv.RoutesRegistererFunc(func(rh serv.RouteHandler) {
+				rh.HandleRoute(serv.Route{
+					Method:  http.MethodGet,
+					Pattern: "/",
+					Handler: HandlerFunc(func(http.ResponseWriter, *http.Request) error {
+						return errors.New("oops")
+					}),
+				})
+			})),
+		)
+		require.NoError(t, err)
+
+		rec := httptest.NewRecorder()
+		base.Server().Handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+		assert.Equal(t, http.StatusTeapot, rec.Code)
+	})
+	t.Run("request details", func(t *testing.T) {
+		var have *logger.RequestDetails
+		handler := logger.AddRequestDetails(http.HandlerFunc(func(wri http.ResponseWriter, req *http.Request) {
+			HandlerFunc(func(http.ResponseWriter, *http.Request) error {
+				return context.DeadlineExceeded
+			}).ServeHTTP(wri, req)
+			have = logger.RequestDetailsFromContext(req.Context())
+		}))
+
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+		assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+		if assert.NotNil(t, have) {
+			assert.Equal(t, ErrorClassTimeout, have.ErrorClass)
+			assert.ErrorIs(t, have.Err, context.DeadlineExceeded)
+		}
+	})
+}
@@ -0,0 +1,167 @@
+// Copyright (c) 2026, Roel Schut. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package webapp
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-pogo/errors"
+	"github.com/go-pogo/healthcheck"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// HealthStatusMetricName is the name of the gauge which reports the
+	// overall health status of the [healthcheck.Checker]: 1 when healthy, -1
+	// when unhealthy and 0 when unknown.
+	HealthStatusMetricName = "webapp.health.status"
+	// HealthCheckStatusMetricName is the name of the gauge which reports the
+	// health status of each registered [healthcheck.HealthChecker], labeled
+	// by its name using [HealthCheckKey].
+	HealthCheckStatusMetricName = "webapp.health.check.status"
+	// HealthCheckDurationMetricName is the name of the histogram which
+	// measures the duration of the health checks of [healthcheck.HealthChecker]s
+	// registered using [Base.Register], see [WithHealthChecker].
+	HealthCheckDurationMetricName = "webapp.health.check.duration"
+)
+
+const (
+	// HealthCheckKey is the attribute key of the name of a registered
+	// [healthcheck.HealthChecker].
+	HealthCheckKey = attribute.Key("webapp.health.check")
+	// HealthStatusKey is the attribute key of a [healthcheck.Status].
+	HealthStatusKey = attribute.Key("webapp.health.status")
+	// HealthPreviousStatusKey is the attribute key of the previous
+	// [healthcheck.Status] when the overall health status changes.
+	HealthPreviousStatusKey = attribute.Key("webapp.health.previous_status")
+)
+
+var _ healthcheck.Registerer = (*Base)(nil)
+
+// Register registers check with the [healthcheck.Checker] created by
+// [WithHealthChecker]. The durations of its health checks are measured using
+// the MeterProvider of the [telemetry.Telemetry]. Register does nothing when
+// health checking is not enabled using [WithHealthChecker]. Like
+// [healthcheck.Checker.Register], it panics when check is nil. It is part of
+// the [healthcheck.Registerer] interface.
+func (base *Base) Register(name string, check healthcheck.HealthChecker) {
+	if base.health == nil {
+		return
+	}
+	if check == nil {
+		// let the checker reject it, instead of wrapping a nil check
+		base.health.Register(name, nil)
+		return
+	}
+	base.health.Register(name, &measuredHealthChecker{
+		base:  base,
+		check: check,
+		attr:  HealthCheckKey.String(name),
+	})
+}
+
+var _ healthcheck.Registerer = (*MeasuredChecker)(nil)
+
+// MeasuredChecker is the [healthcheck.Checker] created by [WithHealthChecker].
+// Unlike [healthcheck.Checker.Register], its Register method measures the
+// durations of the health checks of the registered
+// [healthcheck.HealthChecker].
+type MeasuredChecker struct {
+	*healthcheck.Checker
+	base *Base
+}
+
+// Register registers check using [Base.Register]. It is part of the
+// [healthcheck.Registerer] interface.
+func (mc *MeasuredChecker) Register(name string, check healthcheck.HealthChecker) {
+	mc.base.Register(name, check)
+}
+
+type measuredHealthChecker struct {
+	base  *Base
+	check healthcheck.HealthChecker
+	attr  attribute.KeyValue
+}
+
+func (mc *measuredHealthChecker) CheckHealth(ctx context.Context) healthcheck.Status {
+	start := time.Now()
+	stat := mc.check.CheckHealth(ctx)
+	if h := mc.base.healthDuration; h != nil {
+		h.Record(ctx, time.Since(start).Seconds(), metric.WithAttributes(
+			mc.attr, HealthStatusKey.String(stat.String()),
+		))
+	}
+	return stat
+}
+
+// healthLogger records changes of the overall health status as events on the
+// lifecycle span of [Base], before passing them to the next
+// [healthcheck.Logger].
+type healthLogger struct {
+	base *Base
+	next healthcheck.Logger
+}
+
+func (hl *healthLogger) LogHealthChanged(status, oldStatus healthcheck.Status, details map[string]healthcheck.Status) {
+	if span := hl.base.lifecycle; span != nil {
+		span.AddEvent("health changed", trace.WithAttributes(
+			HealthStatusKey.String(status.String()),
+			HealthPreviousStatusKey.String(oldStatus.String()),
+		))
+	}
+	if hl.next != nil {
+		hl.next.LogHealthChanged(status, oldStatus, details)
+	}
+}
+
+// registerHealthMetrics creates the health check duration histogram and
+// registers the callback which reports the [HealthStatusMetricName] and
+// [HealthCheckStatusMetricName] metrics.
+func (base *Base) registerHealthMetrics() error {
+	if base.health == nil {
+		return nil
+	}
+
+	meter := base.telem.MeterProvider().Meter(meterName)
+	status, err := meter.Int64ObservableGauge(HealthStatusMetricName,
+		metric.WithDescription("Overall health status, 1 when healthy, -1 when unhealthy and 0 when unknown."),
+	)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	checkStatus, err := meter.Int64ObservableGauge(HealthCheckStatusMetricName,
+		metric.WithDescription("Health status per health check, 1 when healthy, -1 when unhealthy and 0 when unknown."),
+	)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	base.healthDuration, err = meter.Float64Histogram(HealthCheckDurationMetricName,
+		metric.WithDescription("Duration of health checks."),
+		metric.WithUnit("s"),
+		metric.WithExplicitBucketBoundaries(0.001, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10),
+	)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	reg, err := meter.RegisterCallback(func(_ context.Context, obs metric.Observer) error {
+		obs.ObserveInt64(status, int64(base.health.Status()))
+		for name, stat := range base.health.Details() {
+			obs.ObserveInt64(checkStatus, int64(stat), metric.WithAttributes(
+				HealthCheckKey.String(name),
+			))
+		}
+		return nil
+	}, status, checkStatus)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	base.callbacks = append(base.callbacks, reg)
+	return nil
+}
@@ -0,0 +1,83 @@
+// Copyright (c) 2026, Roel Schut. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package webapp
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-pogo/healthcheck"
+	"github.com/go-pogo/telemetry"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestBase_Register(t *testing.T) {
+	t.Run("without health checker", func(t *testing.T) {
+		base, err := New()
+		require.NoError(t, err)
+		assert.NotPanics(t, func() {
+			base.Register("db", healthcheck.HealthCheckerFunc(func(context.Context) healthcheck.Status {
+				return healthcheck.StatusHealthy
+			}))
+		})
+	})
+
+	healthy := healthcheck.HealthCheckerFunc(func(context.Context) healthcheck.Status {
+		return healthcheck.StatusHealthy
+	})
+	base, err := New(
+		WithName("test"),
+		// option order should not matter
+		WithHealthCheck("cache", healthy),
+		WithHealthChecker(),
+		WithMetricsRoute(),
+		WithTelemetryConfig(telemetry.Config{}),
+	)
+	require.NoError(t, err)
+
+	rec := tracetest.NewSpanRecorder()
+	_, base.lifecycle = sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(rec)).
+		Tracer("test").
+		Start(context.Background(), "lifecycle")
+
+	base.Register("db", healthcheck.HealthCheckerFunc(func(context.Context) healthcheck.Status {
+		return healthcheck.StatusUnhealthy
+	}))
+	base.HealthChecker().Register("queue", healthy)
+	assert.Panics(t, func() { base.Register("nil", nil) })
+	assert.Panics(t, func() { base.HealthChecker().Register("nil", nil) })
+	assert.Equal(t, healthcheck.StatusUnhealthy, base.health.CheckHealth(context.Background()))
+
+	t.Run("metrics", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		base.router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, MetricsPathPattern, nil))
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		body := rec.Body.String()
+		const scope = `otel_scope_name="github.com/go-pogo/webapp",otel_scope_schema_url="",otel_scope_version=""`
+		assert.Contains(t, body, `webapp_health_status{`+scope+`} -1`)
+		assert.Contains(t, body, `webapp_health_check_status{`+scope+`,webapp_health_check="db"} -1`)
+		assert.Contains(t, body, `webapp_health_check_status{`+scope+`,webapp_health_check="test"} 0`)
+		assert.Contains(t, body, `webapp_health_check_duration_seconds_count{`+scope+`,webapp_health_check="db",webapp_health_status="unhealthy"} 1`)
+		assert.Contains(t, body, `webapp_health_check_duration_seconds_count{`+scope+`,webapp_health_check="cache",webapp_health_status="healthy"} 1`)
+		assert.Contains(t, body, `webapp_health_check_duration_seconds_count{`+scope+`,webapp_health_check="queue",webapp_health_status="healthy"} 1`)
+	})
+	t.Run("span event", func(t *testing.T) {
+		base.lifecycle.End()
+		spans := rec.Ended()
+		require.Len(t, spans, 1)
+		require.Len(t, spans[0].Events(), 1)
+
+		event := spans[0].Events()[0]
+		assert.Equal(t, "health changed", event.Name)
+		assert.Contains(t, event.Attributes, HealthStatusKey.String("unhealthy"))
+		assert.Contains(t, event.Attributes, HealthPreviousStatusKey.String("unknown"))
+	})
+}
@@ -0,0 +1,224 @@
+// Copyright (c) 2026, Roel Schut. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package webapp
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"mime"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/go-pogo/errors"
+	"github.com/go-pogo/webapp/problem"
+)
+
+const (
+	ErrUnsupportedMediaType errors.Msg = "unsupported media type"
+	ErrInvalidBody          errors.Msg = "invalid request body"
+	ErrBodyTooLarge         errors.Msg = "request body too large"
+	ErrValidation           errors.Msg = "validation failed"
+)
+
+// DefaultMaxBodySize is the default max size of a request body decoded by a
+// [JSONHandler].
+const DefaultMaxBodySize int64 = 1 << 20 // 1MB
+
+const contentTypeJSON = "application/json"
+
+// Validator is an optional interface which may be implemented by a request
+// type of a [JSONHandler].
+type Validator interface {
+	Validate() error
+}
+
+// FieldError describes why the value of a single field is invalid.
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+var (
+	_ errors.StatusCoder = (*ValidationError)(nil)
+	_ problem.Extender   = (*ValidationError)(nil)
+)
+
+// ValidationError contains field level details about an invalid request. It
+// is rendered as problem details with its fields listed as "errors" member.
+type ValidationError struct {
+	Status int
+	Fields []FieldError
+	cause  error
+}
+
+// NewValidationError returns a [ValidationError] with status code
+// [http.StatusUnprocessableEntity] and the provided [FieldError]s.
+func NewValidationError(fields ...FieldError) *ValidationError {
+	return &ValidationError{
+		Status: http.StatusUnprocessableEntity,
+		Fields: fields,
+		cause:  errors.New(ErrValidation),
+	}
+}
+
+func (e *ValidationError) Error() string { return e.cause.Error() }
+
+func (e *ValidationError) Unwrap() error { return e.cause }
+
+// StatusCode is part of the [errors.StatusCoder] interface.
+func (e *ValidationError) StatusCode() int { return e.Status }
+
+// ExtendProblem is part of the [problem.Extender] interface.
+func (e *ValidationError) ExtendProblem(d *problem.Details) {
+	if len(e.Fields) != 0 {
+		d.With("errors", e.Fields)
+	}
+}
+
+var _ TypedHandler = (*JSONHandler[any, any])(nil)
+
+// JSONHandler is a [http.Handler] which decodes the request's JSON body into
+// a value of type Req, calls its handler function, and encodes its returned
+// value of type Resp as JSON response. Errors are handled by [HandleError].
+type JSONHandler[Req, Resp any] struct {
+	fn          func(ctx context.Context, req Req) (Resp, error)
+	status      int
+	maxBodySize int64
+}
+
+// JSON returns a [JSONHandler] which calls fn with the decoded request body.
+// Unknown fields in the request body are not allowed. When Req implements
+// [Validator], the decoded value is validated before fn is called.
+func JSON[Req, Resp any](fn func(ctx context.Context, req Req) (Resp, error)) *JSONHandler[Req, Resp] {
+	return &JSONHandler[Req, Resp]{
+		fn:          fn,
+		status:      http.StatusOK,
+		maxBodySize: DefaultMaxBodySize,
+	}
+}
+
+// WithStatus sets the status code of successful responses.
+func (h *JSONHandler[Req, Resp]) WithStatus(code int) *JSONHandler[Req, Resp] {
+	h.status = code
+	return h
+}
+
+// WithMaxBodySize sets the max size of the request body in bytes.
+func (h *JSONHandler[Req, Resp]) WithMaxBodySize(n int64) *JSONHandler[Req, Resp] {
+	h.maxBodySize = n
+	return h
+}
+
+// Types returns the [reflect.Type] of the request and response values. It is
+// part of the [TypedHandler] interface.
+func (h *JSONHandler[Req, Resp]) Types() (req, resp reflect.Type) {
+	return reflect.TypeFor[Req](), reflect.TypeFor[Resp]()
+}
+
+// Status returns the status code of successful responses. It is part of the
+// [TypedHandler] interface.
+func (h *JSONHandler[Req, Resp]) Status() int { return h.status }
+
+// ServeHTTP is part of the [http.Handler] interface.
+func (h *JSONHandler[Req, Resp]) ServeHTTP(wri http.ResponseWriter, req *http.Request) {
+	HandlerFunc(h.serveHTTP).ServeHTTP(wri, req)
+}
+
+func (h *JSONHandler[Req, Resp]) serveHTTP(wri http.ResponseWriter, req *http.Request) error {
+	var in Req
+	if err := h.decode(wri, req, &in); err != nil {
+		return err
+	}
+	if err := validate(&in); err != nil {
+		return err
+	}
+
+	out, err := h.fn(req.Context(), in)
+	if err != nil {
+		return err
+	}
+
+	wri.Header().Set("Content-Type", contentTypeJSON)
+	wri.WriteHeader(h.status)
+	if h.status == http.StatusNoContent || req.Method == http.MethodHead {
+		return nil
+	}
+	return errors.WithStack(json.NewEncoder(wri).Encode(out))
+}
+
+func (h *JSONHandler[Req, Resp]) decode(wri http.ResponseWriter, req *http.Request, v any) error {
+	if req.Body == nil || req.Body == http.NoBody || req.ContentLength == 0 {
+		return nil
+	}
+	if mt, _, _ := mime.ParseMediaType(req.Header.Get("Content-Type")); mt != contentTypeJSON && !strings.HasSuffix(mt, "+json") {
+		return errors.WithStatusCode(errors.New(ErrUnsupportedMediaType), http.StatusUnsupportedMediaType)
+	}
+
+	dec := json.NewDecoder(http.MaxBytesReader(wri, req.Body, h.maxBodySize))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		return decodeError(err)
+	}
+	if dec.More() {
+		return errors.WithStatusCode(errors.New(ErrInvalidBody), http.StatusBadRequest)
+	}
+	return nil
+}
+
+func validate(v any) error {
+	val, ok := v.(Validator)
+	if !ok {
+		// v is always a pointer, also check its element
+		val, ok = reflect.ValueOf(v).Elem().Interface().(Validator)
+	}
+	if !ok {
+		return nil
+	}
+
+	err := val.Validate()
+	if err == nil {
+		return nil
+	}
+
+	var valErr *ValidationError
+	if errors.As(err, &valErr) {
+		return err
+	}
+	if errors.GetStatusCode(err) != 0 {
+		return err
+	}
+	return errors.WithStatusCode(err, http.StatusUnprocessableEntity)
+}
+
+func decodeError(err error) error {
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		return errors.WithStatusCode(errors.New(ErrBodyTooLarge), http.StatusRequestEntityTooLarge)
+	}
+
+	valErr := NewValidationError()
+	valErr.Status = http.StatusBadRequest
+	valErr.cause = errors.Wrap(err, ErrInvalidBody)
+
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		valErr.Fields = append(valErr.Fields, FieldError{
+			Field:   typeErr.Field,
+			Message: "must be of type " + typeErr.Type.String(),
+		})
+	} else if field, ok := strings.CutPrefix(err.Error(), "json: unknown field "); ok {
+		valErr.Fields = append(valErr.Fields, FieldError{
+			Field:   strings.Trim(field, `"`),
+			Message: "unknown field",
+		})
+	} else if errors.Is(err, io.ErrUnexpectedEOF) {
+		valErr.Fields = append(valErr.Fields, FieldError{
+			Message: "unexpected end of body",
+		})
+	}
+	return valErr
+}
@@ -0,0 +1,105 @@
+// Copyright (c) 2026, Roel Schut. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package webapp
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-pogo/webapp/problem"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type greetRequest struct {
+	Name string `json:"name"`
+}
+
+func (r greetRequest) Validate() error {
+	if r.Name == "" {
+		return NewValidationError(FieldError{Field: "name", Message: "is required"})
+	}
+	return nil
+}
+
+type greetResponse struct {
+	Greeting string `json:"greeting"`
+}
+
+func TestJSON(t *testing.T) {
+	handler := JSON(func(_ context.Context, req greetRequest) (greetResponse, error) {
+		return greetResponse{Greeting: "hello " + req.Name}, nil
+	}).WithStatus(http.StatusCreated).WithMaxBodySize(32)
+
+	tests := map[string]struct {
+		contentType string
+		body        string
+		wantCode    int
+		wantBody    string
+		wantErrors  []any
+	}{
+		"success": {
+			contentType: "application/json",
+			body:        `{"name":"world"}`,
+			wantCode:    http.StatusCreated,
+			wantBody:    `{"greeting":"hello world"}` + "\n",
+		},
+		"unknown field": {
+			contentType: "application/json",
+			body:        `{"name":"world","foo":1}`,
+			wantCode:    http.StatusBadRequest,
+			wantErrors:  []any{map[string]any{"field": "foo", "message": "unknown field"}},
+		},
+		"invalid type": {
+			contentType: "application/json",
+			body:        `{"name":1}`,
+			wantCode:    http.StatusBadRequest,
+			wantErrors:  []any{map[string]any{"field": "name", "message": "must be of type string"}},
+		},
+		"validation": {
+			contentType: "application/json",
+			body:        `{"name":""}`,
+			wantCode:    http.StatusUnprocessableEntity,
+			wantErrors:  []any{map[string]any{"field": "name", "message": "is required"}},
+		},
+		"too large": {
+			contentType: "application/json",
+			body:        `{"name":"` + strings.Repeat("x", 64) + `"}`,
+			wantCode:    http.StatusRequestEntityTooLarge,
+		},
+		"unsupported media type": {
+			contentType: "text/plain",
+			body:        `{"name":"world"}`,
+			wantCode:    http.StatusUnsupportedMediaType,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tc.body))
+			req.Header.Set("Content-Type", tc.contentType)
+
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+			assert.Equal(t, tc.wantCode, rec.Code)
+
+			if tc.wantBody != "" {
+				assert.Equal(t, tc.wantBody, rec.Body.String())
+				return
+			}
+
+			assert.Equal(t, problem.ContentType, rec.Header().Get("Content-Type"))
+			if tc.wantErrors != nil {
+				var have map[string]any
+				require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &have))
+				assert.Equal(t, tc.wantErrors, have["errors"])
+			}
+		})
+	}
+}
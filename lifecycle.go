@@ -0,0 +1,220 @@
+// Copyright (c) 2026, Roel Schut. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package webapp
+
+import (
+	"context"
+	"reflect"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/go-pogo/errors"
+	"github.com/go-pogo/serv"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	semconv "go.opentelemetry.io/otel/semconv/v1.40.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// StartTimeMetricName is the name of the gauge which reports the time
+	// [New] was called, in seconds since the unix epoch.
+	StartTimeMetricName = "webapp.start_time"
+	// UptimeMetricName is the name of the gauge which reports the time in
+	// seconds since [New] was called.
+	UptimeMetricName = "webapp.uptime"
+	// InfoMetricName is the name of the gauge which always reports 1, with the
+	// service's name and build information as attributes.
+	InfoMetricName = "webapp.info"
+)
+
+const (
+	// OptionKey is the attribute key of the name of an applied [Option].
+	OptionKey = attribute.Key("webapp.option")
+	// ShutdownTargetKey is the attribute key of the name of a target called
+	// by [Shutdown].
+	ShutdownTargetKey = attribute.Key("webapp.shutdown.target")
+)
+
+// appliedOption records the application of an [Option]. Options are applied
+// before telemetry is set up, their spans are created afterwards.
+type appliedOption struct {
+	name       string
+	start, end time.Time
+	err        error
+}
+
+// traceNew starts the span of [New] and adds the spans of the applied options
+// as its children, using their recorded start and end times.
+func (base *Base) traceNew(applied []appliedOption, optsEnd time.Time) trace.Span {
+	tracer := base.telem.TracerProvider().Tracer(meterName)
+	ctx, span := tracer.Start(context.Background(), "webapp.New",
+		trace.WithTimestamp(base.created),
+	)
+
+	ctx, optsSpan := tracer.Start(ctx, "webapp.options",
+		trace.WithTimestamp(base.created),
+		trace.WithAttributes(attribute.Int("webapp.options.count", len(applied))),
+	)
+	for _, opt := range applied {
+		_, s := tracer.Start(ctx, "webapp.option",
+			trace.WithTimestamp(opt.start),
+			trace.WithAttributes(OptionKey.String(opt.name)),
+		)
+		endSpan(s, opt.err, trace.WithTimestamp(opt.end))
+	}
+	optsSpan.End(trace.WithTimestamp(optsEnd))
+	return span
+}
+
+// registerLifecycleMetrics registers the callback which reports the
+// [StartTimeMetricName], [UptimeMetricName] and [InfoMetricName] metrics.
+func (base *Base) registerLifecycleMetrics(conf *config) error {
+	meter := base.telem.MeterProvider().Meter(meterName)
+	startTime, err := meter.Float64ObservableGauge(StartTimeMetricName,
+		metric.WithDescription("Time the application started, in seconds since the unix epoch."),
+		metric.WithUnit("s"),
+	)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	uptime, err := meter.Float64ObservableGauge(UptimeMetricName,
+		metric.WithDescription("Time since the application started."),
+		metric.WithUnit("s"),
+	)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	info, err := meter.Int64ObservableGauge(InfoMetricName,
+		metric.WithDescription("Build information of the application, the value is always 1."),
+	)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	infoAttrs := metric.WithAttributes(base.infoAttributes(conf)...)
+	created := float64(base.created.UnixNano()) / float64(time.Second)
+
+	reg, err := meter.RegisterCallback(func(_ context.Context, obs metric.Observer) error {
+		obs.ObserveFloat64(startTime, created)
+		obs.ObserveFloat64(uptime, time.Since(base.created).Seconds())
+		obs.ObserveInt64(info, 1, infoAttrs)
+		return nil
+	}, startTime, uptime, info)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	base.callbacks = append(base.callbacks, reg)
+	return nil
+}
+
+func (base *Base) infoAttributes(conf *config) []attribute.KeyValue {
+	attrs := []attribute.KeyValue{
+		semconv.ServiceName(conf.serviceName()),
+		semconv.ProcessRuntimeVersion(runtime.Version()),
+	}
+	if base.build == nil {
+		return attrs
+	}
+	if base.build.Version != "" {
+		attrs = append(attrs, semconv.ServiceVersion(base.build.Version))
+	}
+	if base.build.Revision != "" {
+		attrs = append(attrs, semconv.VCSRefHeadRevision(base.build.Revision))
+	}
+	if base.build.GoVersion != "" {
+		attrs[1] = semconv.ProcessRuntimeVersion(base.build.GoVersion)
+	}
+	return attrs
+}
+
+// serverLogger ends the span of [Base.Run] once the server is started, before
+// passing the start to the next [serv.Logger].
+type serverLogger struct {
+	serv.Logger
+	base *Base
+}
+
+func (sl *serverLogger) LogServerStart(name, addr string) {
+	sl.started(addr)
+	sl.Logger.LogServerStart(name, addr)
+}
+
+func (sl *serverLogger) LogServerStartTLS(name, addr, certFile, keyFile string) {
+	sl.started(addr)
+	sl.Logger.LogServerStartTLS(name, addr, certFile, keyFile)
+}
+
+func (sl *serverLogger) started(addr string) {
+	if span := sl.base.runSpan; span != nil {
+		span.SetAttributes(semconv.ServerAddress(addr))
+		span.End()
+		sl.base.runSpan = nil
+	}
+}
+
+// traceShutdownTarget returns target wrapped so its call is traced as a child
+// span of the span in the context passed to it.
+func traceShutdownTarget(tracer trace.Tracer, target func(ctx context.Context) error) func(ctx context.Context) error {
+	if target == nil {
+		return nil
+	}
+
+	name := funcName(target)
+	return func(ctx context.Context) error {
+		ctx, span := tracer.Start(ctx, "webapp.shutdown.target",
+			trace.WithAttributes(ShutdownTargetKey.String(name)),
+		)
+		err := target(ctx)
+		endSpan(span, err)
+		return err
+	}
+}
+
+// endSpan records err, if any, on span and ends it.
+func endSpan(span trace.Span, err error, opts ...trace.SpanEndOption) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End(opts...)
+}
+
+// funcName returns the name of the function fn, without the suffix of an
+// anonymous function, e.g. "github.com/go-pogo/webapp.WithName".
+func funcName(fn any) string {
+	f := runtime.FuncForPC(reflect.ValueOf(fn).Pointer())
+	if f == nil {
+		return ""
+	}
+
+	name := f.Name()
+	for {
+		i := strings.LastIndexByte(name, '.')
+		if i < 0 || !isAnonymousSuffix(name[i+1:]) {
+			break
+		}
+		name = name[:i]
+	}
+	return strings.TrimSuffix(name, "-fm")
+}
+
+// isAnonymousSuffix reports whether s is a name generated by the compiler for
+// an anonymous function, e.g. "func1" or "1".
+func isAnonymousSuffix(s string) bool {
+	s = strings.TrimPrefix(s, "func")
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
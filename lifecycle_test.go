@@ -0,0 +1,162 @@
+// Copyright (c) 2026, Roel Schut. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package webapp
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-pogo/errors"
+	"github.com/go-pogo/serv"
+	"github.com/go-pogo/telemetry"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.40.0"
+)
+
+func spanNames(spans tracetest.SpanStubs) []string {
+	names := make([]string, 0, len(spans))
+	for _, span := range spans {
+		names = append(names, span.Name)
+	}
+	return names
+}
+
+func TestNew_trace(t *testing.T) {
+	exp := tracetest.NewInMemoryExporter()
+	_, err := New(withTestTracing(exp), WithName("test"))
+	require.NoError(t, err)
+
+	spans := exp.GetSpans()
+	assert.Equal(t, []string{
+		"webapp.option",
+		"webapp.option",
+		"webapp.options",
+		"webapp.New",
+	}, spanNames(spans))
+
+	assert.Contains(t, spans[0].Attributes, OptionKey.String("github.com/go-pogo/webapp.WithTelemetry"))
+	assert.Contains(t, spans[1].Attributes, OptionKey.String("github.com/go-pogo/webapp.WithName"))
+	assert.Equal(t, spans[2].SpanContext.SpanID(), spans[0].Parent.SpanID())
+	assert.Equal(t, spans[3].SpanContext.SpanID(), spans[2].Parent.SpanID())
+	assert.False(t, spans[2].StartTime.After(spans[0].StartTime))
+	assert.False(t, spans[2].EndTime.Before(spans[1].EndTime))
+}
+
+func TestBase_Run(t *testing.T) {
+	// unlike the in memory exporter, the recorder keeps its spans when
+	// telemetry is shut down
+	rec := tracetest.NewSpanRecorder()
+	base, err := New()
+	require.NoError(t, err)
+
+	base.telem = telemetry.New(nil, sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(rec)))
+	base.server.Addr = "127.0.0.1:0"
+
+	done := make(chan error, 1)
+	go func() { done <- base.Run(context.Background()) }()
+	require.Eventually(t, func() bool {
+		return base.server.State() == serv.StateStarted
+	}, time.Second, time.Millisecond)
+
+	require.NoError(t, base.Shutdown(context.Background()))
+	require.NoError(t, <-done)
+
+	spans := tracetest.SpanStubsFromReadOnlySpans(rec.Ended())
+	assert.Equal(t, []string{
+		"webapp.Run",
+		"webapp.shutdown.server",
+		"webapp.Base.Shutdown",
+	}, spanNames(spans))
+	assert.Contains(t, spans[0].Attributes, semconv.ServerAddress("127.0.0.1:0"))
+}
+
+func TestShutdown(t *testing.T) {
+	exp := tracetest.NewInMemoryExporter()
+	prev := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSyncer(exp)))
+	defer otel.SetTracerProvider(prev)
+
+	wantErr := errors.New("some error")
+	err := Shutdown(context.Background(), func(context.Context) error {
+		return wantErr
+	})
+	assert.ErrorIs(t, err, wantErr)
+	assert.ErrorIs(t, err, ErrDuringShutdown)
+
+	spans := exp.GetSpans()
+	require.Len(t, spans, 2)
+	assert.Equal(t, "webapp.shutdown.target", spans[0].Name)
+	assert.Contains(t, spans[0].Attributes, ShutdownTargetKey.String("github.com/go-pogo/webapp.TestShutdown"))
+	assert.Equal(t, codes.Error, spans[0].Status.Code)
+	assert.Equal(t, "webapp.Shutdown", spans[1].Name)
+	assert.Equal(t, spans[1].SpanContext.SpanID(), spans[0].Parent.SpanID())
+
+	t.Run("base", func(t *testing.T) {
+		exp.Reset()
+		baseExp := tracetest.NewInMemoryExporter()
+		base, err := New(withTestTracing(baseExp), WithIsolatedTelemetry())
+		require.NoError(t, err)
+		baseExp.Reset()
+
+		require.NoError(t, base.ShutdownTargets(context.Background(), func(context.Context) error {
+			return nil
+		}))
+		assert.Empty(t, exp.GetSpans(), "global TracerProvider is not used")
+		assert.Equal(t, []string{
+			"webapp.shutdown.target",
+			"webapp.Shutdown",
+		}, spanNames(baseExp.GetSpans()))
+	})
+}
+
+func TestBase_registerLifecycleMetrics(t *testing.T) {
+	base, err := New(
+		WithName("test"),
+		WithMetricsRoute(),
+		WithTelemetryConfig(telemetry.Config{}),
+	)
+	require.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+	base.router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, MetricsPathPattern, nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), "webapp_start_time_seconds")
+	assert.Contains(t, rec.Body.String(), "webapp_uptime_seconds")
+	assert.Contains(t, rec.Body.String(), `webapp_info{`)
+	assert.Contains(t, rec.Body.String(), `service_name="test"`)
+}
+
+func TestFuncName(t *testing.T) {
+	tests := map[string]struct {
+		fn   any
+		want string
+	}{
+		"func": {
+			fn:   WithName,
+			want: "github.com/go-pogo/webapp.WithName",
+		},
+		"anonymous": {
+			fn:   WithName("test"),
+			want: "github.com/go-pogo/webapp.WithName",
+		},
+		"method": {
+			fn:   (&Base{}).Shutdown,
+			want: "github.com/go-pogo/webapp.(*Base).Shutdown",
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.want, funcName(tc.fn))
+		})
+	}
+}
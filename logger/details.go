@@ -0,0 +1,48 @@
+// Copyright (c) 2026, Roel Schut. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package logger
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// RequestDetails contains additional details about a request, which are
+// collected while handling the request and are logged by [Logger.LogAccess].
+type RequestDetails struct {
+	// Route is the pattern of the route which matched the request, including
+	// its method and host when present.
+	Route string
+	// Err is the error returned by the request's handler.
+	Err error
+	// ErrorClass classifies Err.
+	ErrorClass string
+	// Timeout is the route timeout which was exceeded while handling the
+	// request.
+	Timeout time.Duration
+}
+
+type ctxRequestDetailsKey struct{}
+
+// AddRequestDetails adds empty [RequestDetails] to the request's context, so
+// handlers further down the chain can fill them.
+func AddRequestDetails(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(wri http.ResponseWriter, req *http.Request) {
+		if RequestDetailsFromContext(req.Context()) == nil {
+			req = req.WithContext(context.WithValue(req.Context(), ctxRequestDetailsKey{}, new(RequestDetails)))
+		}
+		next.ServeHTTP(wri, req)
+	})
+}
+
+// RequestDetailsFromContext returns the [RequestDetails] from the context
+// values, or nil.
+func RequestDetailsFromContext(ctx context.Context) *RequestDetails {
+	if v, ok := ctx.Value(ctxRequestDetailsKey{}).(*RequestDetails); ok {
+		return v
+	}
+	return nil
+}
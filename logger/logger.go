@@ -18,6 +18,8 @@ import (
 	"github.com/go-pogo/healthcheck/healthclient"
 	"github.com/go-pogo/serv"
 	"github.com/go-pogo/serv/accesslog"
+	"github.com/go-pogo/webapp/auth"
+	"github.com/go-pogo/webapp/problem"
 	"github.com/rs/zerolog"
 	"go.opentelemetry.io/otel"
 )
@@ -35,15 +37,61 @@ type OTELLoggerSetter interface {
 	SetOTELLogger()
 }
 
+// BaggageFieldsSetter sets the names of the baggage members which are added
+// as fields to log lines with a context.
+type BaggageFieldsSetter interface {
+	SetBaggageFields(members ...string)
+}
+
+// HandlerErrorLogger logs errors returned by request handlers.
+type HandlerErrorLogger interface {
+	LogHandlerError(ctx context.Context, err error, req *http.Request)
+}
+
+// DeprecatedRouteLogger logs calls to deprecated routes.
+type DeprecatedRouteLogger interface {
+	LogDeprecatedRoute(ctx context.Context, route serv.Route, client string)
+}
+
+// ClientLogger logs outbound requests made by a http client.
+type ClientLogger interface {
+	LogClientRequest(ctx context.Context, det ClientDetails, req *http.Request)
+}
+
+// ClientDetails contains the details of a single outbound request attempt.
+type ClientDetails struct {
+	// ClientName is the name of the client that made the request.
+	ClientName string
+	// Attempt is the number of the attempt, starting at 1.
+	Attempt int
+	// StatusCode is the response's status code, it is 0 when no response was
+	// received.
+	StatusCode int
+	// Duration of the attempt.
+	Duration time.Duration
+	// Err is the error that occurred during the attempt, if any.
+	Err error
+}
+
 type Config struct {
 	Level         zerolog.Level `env:"LOG_LEVEL" default:"warn" description:"Valid levels are: debug, info, warn, error, fatal, panic"`
 	WithTimestamp bool          `env:"LOG_TIMESTAMP" default:"true" description:"Starts the log's line with a timestamp when true"`
+	// Field names of the trace correlation fields which are added to log
+	// lines with a context that contains a span. Use "-" to omit a field.
+	TraceIDField string `env:"LOG_TRACE_ID_FIELD" default:"trace_id" description:"Name of the trace id field, use - to omit"`
+	SpanIDField  string `env:"LOG_SPAN_ID_FIELD" default:"span_id" description:"Name of the span id field, use - to omit"`
+	SampledField string `env:"LOG_TRACE_SAMPLED_FIELD" default:"trace_sampled" description:"Name of the trace sampled flag field, use - to omit"`
 }
 
 var (
-	_ BuildInfoLogger     = (*Logger)(nil)
-	_ RegisterRouteLogger = (*Logger)(nil)
-	_ OTELLoggerSetter    = (*Logger)(nil)
+	_ BuildInfoLogger       = (*Logger)(nil)
+	_ RegisterRouteLogger   = (*Logger)(nil)
+	_ OTELLoggerSetter      = (*Logger)(nil)
+	_ BaggageFieldsSetter   = (*Logger)(nil)
+	_ ClientLogger          = (*Logger)(nil)
+	_ HandlerErrorLogger    = (*Logger)(nil)
+	_ DeprecatedRouteLogger = (*Logger)(nil)
+	_ problem.PanicLogger   = (*Logger)(nil)
 
 	_ serv.Logger         = (*Logger)(nil)
 	_ accesslog.Logger    = (*Logger)(nil)
@@ -51,8 +99,22 @@ var (
 	_ healthclient.Logger = (*Logger)(nil)
 )
 
-// Logger wraps a [zerolog.Logger] and implements several log interfaces.
-type Logger struct{ zerolog.Logger }
+// Logger wraps a [zerolog.Logger] and implements several log interfaces. Use
+// [New], [NewProductionLogger] or [NewDevelopmentLogger] to create a Logger
+// which adds trace correlation fields to its log lines.
+//
+// Breaking change: Logger has unexported fields, so an unkeyed composite
+// literal like Logger{zl} no longer compiles. Use the keyed literal
+// Logger{Logger: zl} instead, which creates a Logger without trace
+// correlation fields.
+type Logger struct {
+	zerolog.Logger
+	out   io.Writer
+	trace traceFields
+	// hooked is set when the trace correlation fields are added by a
+	// traceHook, see FromContext
+	hooked bool
+}
 
 // NewProductionLogger returns a production ready [Logger].
 func NewProductionLogger(conf Config) *Logger {
@@ -72,7 +134,19 @@ func newLogger(out io.Writer, conf Config) *Logger {
 	if conf.WithTimestamp {
 		log = log.With().Timestamp().Logger()
 	}
-	return &Logger{log}
+	return &Logger{
+		Logger: log,
+		out:    out,
+		trace:  newTraceFields(conf),
+	}
+}
+
+// SetBaggageFields is part of the [BaggageFieldsSetter] interface. The values
+// of the listed baggage members of a context are added as fields, with the
+// member's name prefixed with [BaggageFieldPrefix] as field name, to log lines
+// with that context.
+func (l *Logger) SetBaggageFields(members ...string) {
+	l.trace.baggage = members
 }
 
 // LogBuildInfo is part of the [BuildInfoLogger] interface.
@@ -126,6 +200,17 @@ func (l *Logger) LogRegisterRoute(route serv.Route) {
 		Msg("register route")
 }
 
+// LogDeprecatedRoute is part of the [DeprecatedRouteLogger] interface. Calls
+// are logged as [zerolog.WarnLevel].
+func (l *Logger) LogDeprecatedRoute(ctx context.Context, route serv.Route, client string) {
+	l.addTraceFields(l.Warn(), ctx).
+		Str("name", route.Name).
+		Str("method", route.Method).
+		Str("pattern", route.Pattern).
+		Str("client", client).
+		Msg("deprecated route called")
+}
+
 // LogServerStart is part of the [serv.Logger] interface.
 func (l *Logger) LogServerStart(name, addr string) {
 	l.Info().
@@ -162,7 +247,7 @@ func (l *Logger) LogServerClose(name string) {
 // [zerolog.InfoLevel]. Every status code indicating an error is logged as
 // [zerolog.WarnLevel]. All remaining requests to the [HealthCheckRoute] are
 // logged as [zerolog.DebugLevel]
-func (l *Logger) LogAccess(_ context.Context, det accesslog.Details, req *http.Request) {
+func (l *Logger) LogAccess(ctx context.Context, det accesslog.Details, req *http.Request) {
 	lvl := zerolog.InfoLevel
 	if det.StatusCode >= 400 {
 		lvl = zerolog.WarnLevel
@@ -170,13 +255,29 @@ func (l *Logger) LogAccess(_ context.Context, det accesslog.Details, req *http.R
 		lvl = zerolog.DebugLevel
 	}
 
-	event := l.WithLevel(lvl).
+	event := l.addTraceFields(l.WithLevel(lvl), ctx).
 		Str("server", det.ServerName).
 		Str("handler", det.HandlerName)
 
 	if det.RequestID != "" {
 		event.Str("request_id", det.RequestID)
 	}
+	if p := auth.PrincipalFromContext(ctx); p != nil {
+		event.Str("principal", p.Subject).
+			Str("auth_method", p.Method)
+	}
+	if rd := RequestDetailsFromContext(ctx); rd != nil {
+		if rd.Route != "" {
+			event.Str("route", rd.Route)
+		}
+		if rd.Err != nil {
+			event.Str("error_class", rd.ErrorClass).
+				Str("error", rd.Err.Error())
+		}
+		if rd.Timeout != 0 {
+			event.Dur("timeout", rd.Timeout)
+		}
+	}
 
 	event.Str("user_agent", det.UserAgent).
 		Str("remote_addr", accesslog.RemoteAddr(req)).
@@ -189,6 +290,51 @@ func (l *Logger) LogAccess(_ context.Context, det accesslog.Details, req *http.R
 		Msg(accesslog.Message)
 }
 
+// LogHandlerError is part of the [HandlerErrorLogger] interface. Errors are
+// logged as [zerolog.ErrorLevel]. In dev builds, the error's stack trace is
+// printed as well.
+func (l *Logger) LogHandlerError(ctx context.Context, err error, req *http.Request) {
+	l.addTraceFields(l.Err(err), ctx).
+		Str("method", req.Method).
+		Str("request_uri", accesslog.RequestURI(req)).
+		Msg("handler error")
+}
+
+// LogPanic is part of the [problem.PanicLogger] interface. Panics are logged
+// as [zerolog.ErrorLevel], with their stack.
+func (l *Logger) LogPanic(ctx context.Context, v any, stack []byte, req *http.Request) {
+	l.addTraceFields(l.Error(), ctx).
+		Interface("panic", v).
+		Str("method", req.Method).
+		Str("request_uri", accesslog.RequestURI(req)).
+		Bytes("stack", stack).
+		Msg("recovered panic")
+}
+
+// LogClientRequest is part of the [ClientLogger] interface. Default log level
+// is [zerolog.InfoLevel]. Failed requests and every status code indicating an
+// error are logged as [zerolog.WarnLevel].
+func (l *Logger) LogClientRequest(ctx context.Context, det ClientDetails, req *http.Request) {
+	lvl := zerolog.InfoLevel
+	if det.Err != nil || det.StatusCode >= 400 {
+		lvl = zerolog.WarnLevel
+	}
+
+	event := l.addTraceFields(l.WithLevel(lvl), ctx).
+		Str("client", det.ClientName)
+
+	if det.Err != nil {
+		event.Err(det.Err)
+	}
+
+	event.Str("method", req.Method).
+		Str("url", req.URL.Redacted()).
+		Int("attempt", det.Attempt).
+		Int("status_code", det.StatusCode).
+		Dur("duration", det.Duration).
+		Msg("client request")
+}
+
 // LogHealthChanged is part of the [healthcheck.Logger] interface.
 func (l *Logger) LogHealthChanged(status, oldStatus healthcheck.Status, details map[string]healthcheck.Status) {
 	l.Info().
@@ -0,0 +1,244 @@
+// Copyright (c) 2026, Roel Schut. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+	"go.opentelemetry.io/otel/log"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// InstrumentationScope is the name of the [log.Logger] which is used to emit
+// log records.
+const InstrumentationScope = "github.com/go-pogo/webapp/logger"
+
+// OTELLogsBridge exports logged events through an OTEL [log.LoggerProvider].
+type OTELLogsBridge interface {
+	BridgeOTELLogs(lp log.LoggerProvider)
+}
+
+var _ OTELLogsBridge = (*Logger)(nil)
+
+// BridgeOTELLogs is part of the [OTELLogsBridge] interface. Every event
+// logged by the [Logger] is, in addition to its current output, emitted as log
+// record using a [log.Logger] from lp. Trace correlation fields are used to
+// attach the trace context to the log record. BridgeOTELLogs does nothing
+// when the [Logger] is not created using one of the New functions.
+func (l *Logger) BridgeOTELLogs(lp log.LoggerProvider) {
+	if l.out == nil {
+		return
+	}
+	l.Logger = l.Logger.Output(zerolog.MultiLevelWriter(l.out, &otelWriter{
+		log:   lp.Logger(InstrumentationScope),
+		trace: l.trace,
+	}))
+}
+
+var _ zerolog.LevelWriter = (*otelWriter)(nil)
+
+// otelWriter converts JSON encoded zerolog events and emits them as OTEL log
+// records. The fields of an event are decoded directly into [log.Value]s,
+// without an intermediate map.
+type otelWriter struct {
+	log   log.Logger
+	trace traceFields
+}
+
+func (w *otelWriter) Write(p []byte) (int, error) {
+	return w.WriteLevel(zerolog.NoLevel, p)
+}
+
+func (w *otelWriter) WriteLevel(level zerolog.Level, p []byte) (int, error) {
+	// never fail the other outputs because of the bridge
+	dec := json.NewDecoder(bytes.NewReader(p))
+	dec.UseNumber()
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('{') {
+		return len(p), nil
+	}
+
+	var rec log.Record
+	rec.SetObservedTimestamp(time.Now())
+	rec.SetSeverity(Severity(level))
+	rec.SetSeverityText(level.String())
+
+	var sc trace.SpanContextConfig
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return len(p), nil
+		}
+		key, _ := tok.(string)
+		val, err := decodeValue(dec)
+		if err != nil {
+			return len(p), nil
+		}
+
+		switch key {
+		case zerolog.LevelFieldName:
+			continue
+		case zerolog.MessageFieldName:
+			rec.SetBody(val)
+			continue
+		case zerolog.TimestampFieldName:
+			if val.Kind() == log.KindString {
+				if t, err := time.Parse(zerolog.TimeFieldFormat, val.AsString()); err == nil {
+					rec.SetTimestamp(t)
+					continue
+				}
+			}
+		case w.trace.traceID:
+			if val.Kind() == log.KindString {
+				sc.TraceID, _ = trace.TraceIDFromHex(val.AsString())
+				continue
+			}
+		case w.trace.spanID:
+			if val.Kind() == log.KindString {
+				sc.SpanID, _ = trace.SpanIDFromHex(val.AsString())
+				continue
+			}
+		case w.trace.sampled:
+			if val.Kind() == log.KindBool && val.AsBool() {
+				sc.TraceFlags = trace.FlagsSampled
+				continue
+			}
+		}
+		rec.AddAttributes(log.KeyValue{Key: key, Value: val})
+	}
+
+	ctx := context.Background()
+	if sc.TraceID.IsValid() && sc.SpanID.IsValid() {
+		ctx = trace.ContextWithSpanContext(ctx, trace.NewSpanContext(sc))
+	}
+
+	w.log.Emit(ctx, rec)
+	return len(p), nil
+}
+
+// Severity returns the [log.Severity] of a [zerolog.Level].
+func Severity(level zerolog.Level) log.Severity {
+	switch level {
+	case zerolog.TraceLevel:
+		return log.SeverityTrace
+	case zerolog.DebugLevel:
+		return log.SeverityDebug
+	case zerolog.InfoLevel:
+		return log.SeverityInfo
+	case zerolog.WarnLevel:
+		return log.SeverityWarn
+	case zerolog.ErrorLevel:
+		return log.SeverityError
+	case zerolog.FatalLevel:
+		return log.SeverityFatal
+	case zerolog.PanicLevel:
+		return log.SeverityFatal4
+	default:
+		return log.SeverityUndefined
+	}
+}
+
+// decodeValue decodes the next JSON value of dec as [log.Value].
+func decodeValue(dec *json.Decoder) (log.Value, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return log.Value{}, err
+	}
+
+	switch v := tok.(type) {
+	case string:
+		return log.StringValue(v), nil
+	case bool:
+		return log.BoolValue(v), nil
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return log.Int64Value(i), nil
+		}
+		if f, err := v.Float64(); err == nil && !math.IsInf(f, 0) {
+			return log.Float64Value(f), nil
+		}
+		return log.StringValue(v.String()), nil
+	case json.Delim:
+		if v == '[' {
+			var list []log.Value
+			for dec.More() {
+				item, err := decodeValue(dec)
+				if err != nil {
+					return log.Value{}, err
+				}
+				list = append(list, item)
+			}
+			_, err = dec.Token() // closing ]
+			return log.SliceValue(list...), err
+		}
+
+		var kvs []log.KeyValue
+		for dec.More() {
+			tok, err := dec.Token()
+			if err != nil {
+				return log.Value{}, err
+			}
+			key, _ := tok.(string)
+			item, err := decodeValue(dec)
+			if err != nil {
+				return log.Value{}, err
+			}
+			kvs = append(kvs, log.KeyValue{Key: key, Value: item})
+		}
+		_, err = dec.Token() // closing }
+		return log.MapValue(kvs...), err
+	default:
+		// null
+		return log.Value{}, nil
+	}
+}
+
+var _ sdklog.Exporter = (*InMemoryExporter)(nil)
+
+// InMemoryExporter is a [sdklog.Exporter] which stores exported log records
+// in memory. It is intended for testing.
+type InMemoryExporter struct {
+	mut     sync.Mutex
+	records []sdklog.Record
+}
+
+// NewInMemoryExporter returns a new [InMemoryExporter].
+func NewInMemoryExporter() *InMemoryExporter { return new(InMemoryExporter) }
+
+// Export is part of the [sdklog.Exporter] interface.
+func (e *InMemoryExporter) Export(_ context.Context, records []sdklog.Record) error {
+	e.mut.Lock()
+	defer e.mut.Unlock()
+	for _, rec := range records {
+		e.records = append(e.records, rec.Clone())
+	}
+	return nil
+}
+
+// Records returns a copy of all exported log records.
+func (e *InMemoryExporter) Records() []sdklog.Record {
+	e.mut.Lock()
+	defer e.mut.Unlock()
+	return append(make([]sdklog.Record, 0, len(e.records)), e.records...)
+}
+
+// Reset removes all exported log records.
+func (e *InMemoryExporter) Reset() {
+	e.mut.Lock()
+	e.records = nil
+	e.mut.Unlock()
+}
+
+// Shutdown is part of the [sdklog.Exporter] interface.
+func (e *InMemoryExporter) Shutdown(context.Context) error { return nil }
+
+// ForceFlush is part of the [sdklog.Exporter] interface.
+func (e *InMemoryExporter) ForceFlush(context.Context) error { return nil }
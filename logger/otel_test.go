@@ -0,0 +1,74 @@
+// Copyright (c) 2026, Roel Schut. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package logger
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/log"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestLogger_BridgeOTELLogs(t *testing.T) {
+	var buf bytes.Buffer
+	exp := NewInMemoryExporter()
+	l := newLogger(&buf, Config{Level: zerolog.DebugLevel})
+	l.BridgeOTELLogs(sdklog.NewLoggerProvider(sdklog.WithProcessor(sdklog.NewSimpleProcessor(exp))))
+
+	traceID, _ := trace.TraceIDFromHex("0102030405060708090a0b0c0d0e0f10")
+	spanID, _ := trace.SpanIDFromHex("0102030405060708")
+	ctx := trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    traceID,
+		SpanID:     spanID,
+		TraceFlags: trace.FlagsSampled,
+	}))
+
+	FromContext(ContextWithLogger(ctx, l)).Warn().
+		Str("str", "value").
+		Int("int", 42).
+		Bool("bool", true).
+		Strs("list", []string{"a", "b"}).
+		Dict("dict", zerolog.Dict().Float64("float", 1.5)).
+		Msg("hello")
+	l.Trace().Msg("ignored")
+
+	assert.Contains(t, buf.String(), `"message":"hello"`)
+
+	records := exp.Records()
+	require.Len(t, records, 1)
+
+	rec := records[0]
+	assert.Equal(t, log.SeverityWarn, rec.Severity())
+	assert.Equal(t, "warn", rec.SeverityText())
+	assert.Equal(t, log.StringValue("hello"), rec.Body())
+	assert.Equal(t, traceID, rec.TraceID())
+	assert.Equal(t, spanID, rec.SpanID())
+	assert.True(t, rec.TraceFlags().IsSampled())
+
+	attrs := make(map[string]log.Value)
+	rec.WalkAttributes(func(kv log.KeyValue) bool {
+		attrs[kv.Key] = kv.Value
+		return true
+	})
+	assert.Equal(t, map[string]log.Value{
+		"str":  log.StringValue("value"),
+		"int":  log.Int64Value(42),
+		"bool": log.BoolValue(true),
+		"list": log.SliceValue(log.StringValue("a"), log.StringValue("b")),
+		"dict": log.MapValue(log.Float64("float", 1.5)),
+	}, attrs)
+}
+
+func TestSeverity(t *testing.T) {
+	assert.Equal(t, log.SeverityInfo, Severity(zerolog.InfoLevel))
+	assert.Equal(t, log.SeverityError, Severity(zerolog.ErrorLevel))
+	assert.Equal(t, log.SeverityUndefined, Severity(zerolog.NoLevel))
+}
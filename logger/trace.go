@@ -0,0 +1,146 @@
+// Copyright (c) 2026, Roel Schut. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package logger
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/rs/zerolog"
+	"go.opentelemetry.io/otel/baggage"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Default field names of trace correlation fields.
+const (
+	DefaultTraceIDField = "trace_id"
+	DefaultSpanIDField  = "span_id"
+	DefaultSampledField = "trace_sampled"
+)
+
+// BaggageFieldPrefix prefixes the name of a baggage member to form its field
+// name, so baggage members cannot overwrite other fields.
+const BaggageFieldPrefix = "baggage."
+
+// traceFields contains the field names of the trace correlation fields. An
+// empty name disables the field. Baggage contains the names of the baggage
+// members which are added as fields.
+type traceFields struct {
+	traceID string
+	spanID  string
+	sampled string
+	baggage []string
+}
+
+func newTraceFields(conf Config) traceFields {
+	return traceFields{
+		traceID: fieldName(conf.TraceIDField, DefaultTraceIDField),
+		spanID:  fieldName(conf.SpanIDField, DefaultSpanIDField),
+		sampled: fieldName(conf.SampledField, DefaultSampledField),
+	}
+}
+
+// fieldName returns name, or def when name is empty. A name of "-" disables
+// the field.
+func fieldName(name, def string) string {
+	switch name {
+	case "":
+		return def
+	case "-":
+		return ""
+	default:
+		return name
+	}
+}
+
+// addTo adds the trace and span IDs, and the sampled flag, of the span in ctx
+// to event, as well as the listed baggage members of ctx.
+func (tf traceFields) addTo(event *zerolog.Event, ctx context.Context) *zerolog.Event {
+	if ctx == nil {
+		return event
+	}
+	if len(tf.baggage) != 0 {
+		bag := baggage.FromContext(ctx)
+		for _, name := range tf.baggage {
+			if m := bag.Member(name); m.Key() != "" {
+				event.Str(BaggageFieldPrefix+name, m.Value())
+			}
+		}
+	}
+
+	sc := trace.SpanContextFromContext(ctx)
+	if !sc.IsValid() {
+		return event
+	}
+	if tf.traceID != "" {
+		event.Str(tf.traceID, sc.TraceID().String())
+	}
+	if tf.spanID != "" {
+		event.Str(tf.spanID, sc.SpanID().String())
+	}
+	if tf.sampled != "" {
+		event.Bool(tf.sampled, sc.IsSampled())
+	}
+	return event
+}
+
+// addTraceFields adds the trace correlation fields of ctx to event, unless
+// they are already added by the traceHook of a [Logger] from [FromContext].
+func (l *Logger) addTraceFields(event *zerolog.Event, ctx context.Context) *zerolog.Event {
+	if l.hooked {
+		return event
+	}
+	return l.trace.addTo(event, ctx)
+}
+
+// traceHook adds the trace correlation fields of the span in the event's
+// context to each event.
+type traceHook traceFields
+
+func (h traceHook) Run(event *zerolog.Event, _ zerolog.Level, _ string) {
+	traceFields(h).addTo(event, event.GetCtx())
+}
+
+type ctxLoggerKey struct{}
+
+// ContextWithLogger returns a copy of ctx which holds l.
+func ContextWithLogger(ctx context.Context, l *Logger) context.Context {
+	return context.WithValue(ctx, ctxLoggerKey{}, l)
+}
+
+// AddLogger adds l to the request's context, so it can be retrieved using
+// [FromContext].
+func AddLogger(l *Logger, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(wri http.ResponseWriter, req *http.Request) {
+		next.ServeHTTP(wri, req.WithContext(ContextWithLogger(req.Context(), l)))
+	})
+}
+
+// FromContext returns a copy of the [Logger] from ctx which adds the trace and
+// span IDs, and sampled flag, of the span in ctx to each logged event. When
+// ctx does not hold a [Logger], the [zerolog.Logger] from [zerolog.Ctx] is
+// used with the default trace correlation fields. This is a disabled logger
+// when ctx does not hold a [zerolog.Logger] either and no
+// [zerolog.DefaultContextLogger] is set.
+func FromContext(ctx context.Context) *Logger {
+	l, _ := ctx.Value(ctxLoggerKey{}).(*Logger)
+	if l == nil {
+		l = &Logger{
+			Logger: *zerolog.Ctx(ctx),
+			trace:  newTraceFields(Config{}),
+		}
+	}
+
+	zl := l.Logger.With().Ctx(ctx).Logger()
+	if !l.hooked {
+		zl = zl.Hook(traceHook(l.trace))
+	}
+	return &Logger{
+		Logger: zl,
+		out:    l.out,
+		trace:  l.trace,
+		hooked: true,
+	}
+}
@@ -0,0 +1,119 @@
+// Copyright (c) 2026, Roel Schut. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/go-pogo/serv"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/baggage"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestFromContext(t *testing.T) {
+	traceID, _ := trace.TraceIDFromHex("0102030405060708090a0b0c0d0e0f10")
+	spanID, _ := trace.SpanIDFromHex("0102030405060708")
+	ctx := trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    traceID,
+		SpanID:     spanID,
+		TraceFlags: trace.FlagsSampled,
+	}))
+
+	tests := map[string]struct {
+		conf Config
+		want map[string]any
+	}{
+		"default fields": {
+			want: map[string]any{
+				DefaultTraceIDField: traceID.String(),
+				DefaultSpanIDField:  spanID.String(),
+				DefaultSampledField: true,
+			},
+		},
+		"custom fields": {
+			conf: Config{TraceIDField: "dd.trace_id", SpanIDField: "dd.span_id", SampledField: "-"},
+			want: map[string]any{
+				"dd.trace_id": traceID.String(),
+				"dd.span_id":  spanID.String(),
+			},
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			var buf bytes.Buffer
+			tc.conf.Level = zerolog.InfoLevel
+
+			FromContext(ContextWithLogger(ctx, newLogger(&buf, tc.conf))).Info().Msg("test")
+
+			var have map[string]any
+			require.NoError(t, json.Unmarshal(buf.Bytes(), &have))
+			delete(have, "level")
+			delete(have, "message")
+			assert.Equal(t, tc.want, have)
+		})
+	}
+
+	t.Run("without logger", func(t *testing.T) {
+		assert.NotPanics(t, func() {
+			FromContext(ctx).Info().Msg("test")
+		})
+	})
+	t.Run("zerolog logger", func(t *testing.T) {
+		var buf bytes.Buffer
+		zl := zerolog.New(&buf)
+		FromContext(zl.WithContext(ctx)).Info().Msg("test")
+		assert.Contains(t, buf.String(), `"`+DefaultTraceIDField+`":"`+traceID.String()+`"`)
+	})
+	t.Run("fields once", func(t *testing.T) {
+		var buf bytes.Buffer
+		l := FromContext(ContextWithLogger(ctx, newLogger(&buf, Config{Level: zerolog.InfoLevel})))
+		l.LogDeprecatedRoute(ctx, serv.Route{Name: "old"}, "client")
+		// and from a logger of a logger from FromContext
+		FromContext(ContextWithLogger(ctx, l)).Info().Msg("test")
+
+		for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+			assert.Equal(t, 1, strings.Count(line, DefaultTraceIDField), line)
+		}
+	})
+	t.Run("keyed literal", func(t *testing.T) {
+		var buf bytes.Buffer
+		l := &Logger{Logger: zerolog.New(&buf)}
+		l.LogDeprecatedRoute(ctx, serv.Route{Name: "old"}, "client")
+		FromContext(ContextWithLogger(ctx, l)).Info().Msg("test")
+
+		assert.Equal(t, 2, strings.Count(buf.String(), "\n"))
+		assert.NotContains(t, buf.String(), DefaultTraceIDField)
+	})
+	t.Run("without span", func(t *testing.T) {
+		var buf bytes.Buffer
+		l := newLogger(&buf, Config{Level: zerolog.InfoLevel})
+		FromContext(ContextWithLogger(context.Background(), l)).Info().Msg("test")
+		assert.NotContains(t, buf.String(), DefaultTraceIDField)
+	})
+	t.Run("baggage", func(t *testing.T) {
+		tenant, _ := baggage.NewMember("tenant", "acme")
+		secret, _ := baggage.NewMember("secret", "foo")
+		bag, _ := baggage.New(tenant, secret)
+
+		var buf bytes.Buffer
+		l := newLogger(&buf, Config{Level: zerolog.InfoLevel})
+		l.SetBaggageFields("tenant", "region")
+		FromContext(ContextWithLogger(baggage.ContextWithBaggage(context.Background(), bag), l)).Info().Msg("test")
+
+		var have map[string]any
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &have))
+		assert.Equal(t, "acme", have[BaggageFieldPrefix+"tenant"])
+		assert.NotContains(t, have, "tenant")
+		assert.NotContains(t, have, BaggageFieldPrefix+"secret")
+		assert.NotContains(t, have, BaggageFieldPrefix+"region")
+	})
+}
@@ -0,0 +1,102 @@
+// Copyright (c) 2026, Roel Schut. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package webapp
+
+import (
+	"context"
+
+	"github.com/go-pogo/errors"
+	"github.com/go-pogo/webapp/logger"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlplog/otlploggrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlplog/otlploghttp"
+	"go.opentelemetry.io/otel/log/global"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+	"go.opentelemetry.io/otel/sdk/resource"
+	semconv "go.opentelemetry.io/otel/semconv/v1.40.0"
+)
+
+// ErrUnsupportedLogsProtocol is returned by [New] when [WithOTELLogs] is used
+// with an OTLP endpoint whose protocol is not supported for exporting logs.
+const ErrUnsupportedLogsProtocol errors.Msg = "unsupported otlp logs protocol"
+
+// WithOTELLogs exports all events logged by the [Logger] set with [WithLogger]
+// through an OTEL [sdklog.LoggerProvider], when the [Logger] implements
+// [logger.OTELLogsBridge]. The resource of the log records has the same
+// attributes as the resource of the tracer and meter providers created with
+// [WithTelemetryConfig]. Log records are exported in batches to the configured
+// OTLP endpoint, using the grpc or http/protobuf protocol. [New] returns an
+// error matching [ErrUnsupportedLogsProtocol] for any other protocol. Log
+// records are also exported to the provided exporters, without batching,
+// which is useful for testing (see [logger.InMemoryExporter]).
+func WithOTELLogs(exporters ...sdklog.Exporter) Option {
+	return func(_ *Base, config *config) error {
+		config.otelLogs = true
+		config.logExporters = append(config.logExporters, exporters...)
+		return nil
+	}
+}
+
+// LoggerProvider returns the [sdklog.LoggerProvider] created by [WithOTELLogs],
+// or nil.
+func (base *Base) LoggerProvider() *sdklog.LoggerProvider { return base.logs }
+
+// setupOTELLogs creates the [sdklog.LoggerProvider] and bridges the logger to
+// it. It is called after all options are applied, so the order of options
+// does not matter.
+func (base *Base) setupOTELLogs(conf *config) error {
+	if !conf.otelLogs {
+		return nil
+	}
+
+	// same as the resource of the telemetry.TracerProviderBuilder
+	attrs := append([]attribute.KeyValue{semconv.ServiceName(conf.serviceName())}, conf.resourceAttrs...)
+	res, err := resource.Merge(resource.Default(), resource.NewSchemaless(attrs...))
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	opts := []sdklog.LoggerProviderOption{sdklog.WithResource(res)}
+	if tc := conf.telemetry; tc != nil && tc.ExporterOTLP.Endpoint != "" {
+		exp, err := newOTLPLogExporter(tc.ExporterOTLP.Protocol)
+		if err != nil {
+			return err
+		}
+		opts = append(opts, sdklog.WithProcessor(sdklog.NewBatchProcessor(exp)))
+	}
+	for _, exp := range conf.logExporters {
+		opts = append(opts, sdklog.WithProcessor(sdklog.NewSimpleProcessor(exp)))
+	}
+
+	base.logs = sdklog.NewLoggerProvider(opts...)
+	if !conf.isolated {
+		global.SetLoggerProvider(base.logs)
+	}
+
+	if bridge, ok := conf.logger.(logger.OTELLogsBridge); ok {
+		bridge.BridgeOTELLogs(base.logs)
+	}
+	return nil
+}
+
+// newOTLPLogExporter creates the OTLP log exporter for protocol. Its
+// configuration is read from the OTEL_EXPORTER_OTLP_* environment variables,
+// which are set when building the telemetry.
+func newOTLPLogExporter(protocol string) (sdklog.Exporter, error) {
+	var exp sdklog.Exporter
+	var err error
+	switch protocol {
+	case "grpc":
+		exp, err = otlploggrpc.New(context.Background())
+	case "http/protobuf":
+		exp, err = otlploghttp.New(context.Background())
+	default:
+		return nil, errors.Errorf("%w: %s", ErrUnsupportedLogsProtocol, protocol)
+	}
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return exp, nil
+}
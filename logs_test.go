@@ -0,0 +1,71 @@
+// Copyright (c) 2026, Roel Schut. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package webapp
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-pogo/buildinfo"
+	"github.com/go-pogo/telemetry"
+	"github.com/go-pogo/webapp/logger"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	semconv "go.opentelemetry.io/otel/semconv/v1.40.0"
+)
+
+func TestWithOTELLogs(t *testing.T) {
+	exp := logger.NewInMemoryExporter()
+	log := logger.NewProductionLogger(logger.Config{Level: zerolog.InfoLevel})
+
+	// option order should not matter
+	base, err := New(WithOTELLogs(exp), WithName("test"), WithLogger(log))
+	require.NoError(t, err)
+	require.NotNil(t, base.LoggerProvider())
+
+	log.Info().Msg("hello")
+	require.Len(t, exp.Records(), 1)
+	assert.Equal(t, "hello", exp.Records()[0].Body().AsString())
+	assert.NoError(t, base.LoggerProvider().Shutdown(context.Background()))
+
+	t.Run("resource", func(t *testing.T) {
+		exp := logger.NewInMemoryExporter()
+		log := logger.NewProductionLogger(logger.Config{Level: zerolog.InfoLevel})
+
+		base, err := New(
+			WithTelemetryConfig(telemetry.Config{}),
+			WithIsolatedTelemetry(),
+			WithBuildInfo(&buildinfo.BuildInfo{Version: "v1.2.3"}),
+			WithOTELLogs(exp),
+			WithName("test"),
+			WithLogger(log),
+		)
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = base.Shutdown(context.Background()) })
+
+		log.Info().Msg("hello")
+		require.Len(t, exp.Records(), 1)
+		res := exp.Records()[0].Resource()
+		assert.Contains(t, res.Attributes(), semconv.ServiceName("test"))
+		_, ok := res.Set().Value(semconv.ServiceVersionKey)
+		assert.True(t, ok, "resource has the build attributes")
+	})
+}
+
+func TestNewOTLPLogExporter(t *testing.T) {
+	for _, protocol := range []string{"grpc", "http/protobuf"} {
+		t.Run(protocol, func(t *testing.T) {
+			exp, err := newOTLPLogExporter(protocol)
+			require.NoError(t, err)
+			assert.NoError(t, exp.Shutdown(context.Background()))
+		})
+	}
+	t.Run("unsupported", func(t *testing.T) {
+		_, err := newOTLPLogExporter("http/json")
+		assert.ErrorIs(t, err, ErrUnsupportedLogsProtocol)
+		assert.ErrorContains(t, err, "http/json")
+	})
+}
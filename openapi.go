@@ -0,0 +1,44 @@
+// Copyright (c) 2026, Roel Schut. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package webapp
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-pogo/serv"
+	"github.com/go-pogo/webapp/openapi"
+)
+
+// OpenAPIDocument generates an [openapi.Document] from routes and their
+// attached [RouteMeta].
+func OpenAPIDocument(info openapi.Info, routes ...serv.Route) *openapi.Document {
+	list := make([]openapi.Route, 0, len(routes))
+	for _, route := range routes {
+		meta, _ := RouteMetaOf(route)
+		list = append(list, openapi.Route{
+			Name:           route.Name,
+			Method:         route.Method,
+			Pattern:        route.Pattern,
+			Summary:        meta.Summary,
+			Description:    meta.Description,
+			Tags:           meta.Tags,
+			Request:        meta.Request,
+			Response:       meta.Response,
+			ResponseStatus: meta.ResponseStatus,
+			Deprecated:     meta.Deprecation != nil,
+		})
+	}
+	return openapi.New(info, list...)
+}
+
+// OpenAPIHTTPHandler returns a [http.Handler] which responds with the
+// [openapi.Document] of all routes returned by routes.
+func OpenAPIHTTPHandler(info openapi.Info, routes func() []serv.Route) http.Handler {
+	return http.HandlerFunc(func(wri http.ResponseWriter, _ *http.Request) {
+		wri.Header().Set("Content-Type", contentTypeJSON)
+		_ = json.NewEncoder(wri).Encode(OpenAPIDocument(info, routes()...))
+	})
+}
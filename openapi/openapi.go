@@ -0,0 +1,255 @@
+// Copyright (c) 2026, Roel Schut. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package openapi generates OpenAPI 3.1 documents from routes.
+package openapi
+
+import (
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Version is the OpenAPI specification version of generated documents.
+const Version = "3.1.0"
+
+const (
+	contentTypeJSON    = "application/json"
+	contentTypeProblem = "application/problem+json"
+	problemSchemaName  = "Problem"
+)
+
+// Document is an OpenAPI document.
+type Document struct {
+	OpenAPI    string               `json:"openapi"`
+	Info       Info                 `json:"info"`
+	Paths      map[string]*PathItem `json:"paths"`
+	Components *Components          `json:"components,omitempty"`
+}
+
+// Info contains metadata about the API.
+type Info struct {
+	Title       string `json:"title"`
+	Version     string `json:"version"`
+	Description string `json:"description,omitempty"`
+}
+
+// PathItem describes the operations available on a single path, keyed by
+// lowercase method.
+type PathItem map[string]*Operation
+
+// Operation describes a single API operation on a path.
+type Operation struct {
+	OperationID string               `json:"operationId,omitempty"`
+	Summary     string               `json:"summary,omitempty"`
+	Description string               `json:"description,omitempty"`
+	Tags        []string             `json:"tags,omitempty"`
+	Parameters  []Parameter          `json:"parameters,omitempty"`
+	RequestBody *RequestBody         `json:"requestBody,omitempty"`
+	Responses   map[string]*Response `json:"responses"`
+	Deprecated  bool                 `json:"deprecated,omitempty"`
+}
+
+// Parameter describes a single operation parameter.
+type Parameter struct {
+	Name     string  `json:"name"`
+	In       string  `json:"in"`
+	Required bool    `json:"required"`
+	Schema   *Schema `json:"schema,omitempty"`
+}
+
+// RequestBody describes a request body.
+type RequestBody struct {
+	Required bool                  `json:"required"`
+	Content  map[string]*MediaType `json:"content"`
+}
+
+// Response describes a single response of an operation.
+type Response struct {
+	Description string                `json:"description"`
+	Content     map[string]*MediaType `json:"content,omitempty"`
+}
+
+// MediaType describes the schema of a media type.
+type MediaType struct {
+	Schema *Schema `json:"schema,omitempty"`
+}
+
+// Components holds reusable schemas.
+type Components struct {
+	Schemas map[string]*Schema `json:"schemas,omitempty"`
+}
+
+// Route describes a route to add to a [Document].
+type Route struct {
+	Name        string
+	Method      string
+	Pattern     string
+	Summary     string
+	Description string
+	Tags        []string
+	// Request is the type of the request body, if any.
+	Request reflect.Type
+	// Response is the type of the response body, if any.
+	Response reflect.Type
+	// ResponseStatus is the status code of a successful response. It defaults
+	// to [http.StatusOK].
+	ResponseStatus int
+	// Deprecated marks the route's operation as deprecated.
+	Deprecated bool
+}
+
+// New generates a new [Document] from the provided routes. Path parameters
+// are parsed from the routes' patterns. Request and response schemas are
+// derived from the routes' types using reflection. Routes without a method
+// match any method and are documented as a GET, POST, PUT, PATCH and DELETE
+// operation, except for the methods of other routes with the same path.
+func New(info Info, routes ...Route) *Document {
+	doc := &Document{
+		OpenAPI: Version,
+		Info:    info,
+		Paths:   make(map[string]*PathItem, len(routes)),
+	}
+
+	gen := newGenerator()
+	// routes with a method take precedence over routes without
+	for _, route := range routes {
+		if route.Method != "" {
+			path, params := ParsePattern(route.Pattern)
+			doc.pathItem(path)[strings.ToLower(route.Method)] = gen.operation(route, route.Method, route.Name, params)
+		}
+	}
+	for _, route := range routes {
+		if route.Method != "" {
+			continue
+		}
+
+		path, params := ParsePattern(route.Pattern)
+		item := doc.pathItem(path)
+		for _, method := range anyMethods {
+			key := strings.ToLower(method)
+			if _, exists := item[key]; exists {
+				continue
+			}
+
+			var id string
+			if route.Name != "" {
+				id = route.Name + "." + key
+			}
+			item[key] = gen.operation(route, method, id, params)
+		}
+	}
+
+	if len(gen.schemas) != 0 {
+		doc.Components = &Components{Schemas: gen.schemas}
+	}
+	return doc
+}
+
+// anyMethods are the methods a route without a method is documented with.
+var anyMethods = []string{
+	http.MethodGet,
+	http.MethodPost,
+	http.MethodPut,
+	http.MethodPatch,
+	http.MethodDelete,
+}
+
+func (doc *Document) pathItem(path string) PathItem {
+	item, ok := doc.Paths[path]
+	if !ok {
+		item = new(PathItem)
+		*item = make(PathItem, 1)
+		doc.Paths[path] = item
+	}
+	return *item
+}
+
+func (gen *generator) operation(route Route, method, id string, params []string) *Operation {
+	op := &Operation{
+		OperationID: id,
+		Summary:     route.Summary,
+		Description: route.Description,
+		Tags:        route.Tags,
+		Responses:   make(map[string]*Response, 2),
+		Deprecated:  route.Deprecated,
+	}
+	for _, p := range params {
+		op.Parameters = append(op.Parameters, Parameter{
+			Name:     p,
+			In:       "path",
+			Required: true,
+			Schema:   &Schema{Type: "string"},
+		})
+	}
+	if hasBody(method) && !isEmpty(route.Request) {
+		op.RequestBody = &RequestBody{
+			Required: true,
+			Content: map[string]*MediaType{
+				contentTypeJSON: {Schema: gen.schema(route.Request)},
+			},
+		}
+	}
+
+	status := route.ResponseStatus
+	if status == 0 {
+		status = http.StatusOK
+	}
+	resp := &Response{Description: http.StatusText(status)}
+	if !isEmpty(route.Response) && status != http.StatusNoContent {
+		resp.Content = map[string]*MediaType{
+			contentTypeJSON: {Schema: gen.schema(route.Response)},
+		}
+	}
+	op.Responses[strconv.Itoa(status)] = resp
+	op.Responses["default"] = &Response{
+		Description: "Error",
+		Content: map[string]*MediaType{
+			contentTypeProblem: {Schema: gen.problemSchema()},
+		},
+	}
+	return op
+}
+
+// ParsePattern parses a [http.ServeMux] pattern and returns its path in
+// OpenAPI format, without host, and the names of its wildcards.
+//
+//	ParsePattern("/users/{id}/files/{path...}")
+//	// "/users/{id}/files/{path}", []string{"id", "path"}
+func ParsePattern(pattern string) (path string, params []string) {
+	if i := strings.IndexByte(pattern, '/'); i > 0 {
+		pattern = pattern[i:]
+	}
+
+	segments := strings.Split(pattern, "/")
+	for i, seg := range segments {
+		if len(seg) < 2 || seg[0] != '{' || seg[len(seg)-1] != '}' {
+			continue
+		}
+
+		name := strings.TrimSuffix(seg[1:len(seg)-1], "...")
+		if name == "$" {
+			segments[i] = ""
+			continue
+		}
+
+		segments[i] = "{" + name + "}"
+		params = append(params, name)
+	}
+	return strings.Join(segments, "/"), params
+}
+
+func hasBody(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch:
+		return true
+	default:
+		return false
+	}
+}
+
+func isEmpty(typ reflect.Type) bool {
+	return typ == nil || (typ.Kind() == reflect.Struct && typ.NumField() == 0)
+}
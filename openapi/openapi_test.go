@@ -0,0 +1,109 @@
+// Copyright (c) 2026, Roel Schut. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package openapi
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type item struct {
+	ID       int       `json:"id"`
+	Name     string    `json:"name"`
+	Tags     []string  `json:"tags,omitempty"`
+	Created  time.Time `json:"created"`
+	Parent   *item     `json:"parent"`
+	internal bool
+}
+
+type createItem struct {
+	Name string `json:"name"`
+}
+
+func TestParsePattern(t *testing.T) {
+	tests := map[string]struct {
+		path   string
+		params []string
+	}{
+		"/":                           {path: "/"},
+		"/{$}":                        {path: "/"},
+		"/items/{id}":                 {path: "/items/{id}", params: []string{"id"}},
+		"example.com/files/{path...}": {path: "/files/{path}", params: []string{"path"}},
+		"/a/{x}/b/{y}/{$}":            {path: "/a/{x}/b/{y}/", params: []string{"x", "y"}},
+	}
+	for pattern, tc := range tests {
+		t.Run(pattern, func(t *testing.T) {
+			path, params := ParsePattern(pattern)
+			assert.Equal(t, tc.path, path)
+			assert.Equal(t, tc.params, params)
+		})
+	}
+}
+
+func TestNew(t *testing.T) {
+	doc := New(Info{Title: "test", Version: "v1"},
+		Route{
+			Name:     "items.get",
+			Method:   http.MethodGet,
+			Pattern:  "/items/{id}",
+			Summary:  "Get item",
+			Response: reflect.TypeFor[item](),
+		},
+		Route{
+			Name:           "items.create",
+			Method:         http.MethodPost,
+			Pattern:        "/items",
+			Request:        reflect.TypeFor[createItem](),
+			Response:       reflect.TypeFor[*item](),
+			ResponseStatus: http.StatusCreated,
+		},
+		Route{Name: "health", Pattern: "/healthy"},
+		Route{Name: "any", Pattern: "/items"},
+	)
+
+	assert.Equal(t, Version, doc.OpenAPI)
+	require.Len(t, doc.Paths, 3)
+
+	get := (*doc.Paths["/items/{id}"])["get"]
+	require.NotNil(t, get)
+	assert.Equal(t, "items.get", get.OperationID)
+	assert.Equal(t, []Parameter{{
+		Name:     "id",
+		In:       "path",
+		Required: true,
+		Schema:   &Schema{Type: "string"},
+	}}, get.Parameters)
+	assert.Nil(t, get.RequestBody)
+	assert.Equal(t, "#/components/schemas/item", get.Responses["200"].Content[contentTypeJSON].Schema.Ref)
+	assert.Equal(t, "#/components/schemas/Problem", get.Responses["default"].Content[contentTypeProblem].Schema.Ref)
+
+	create := (*doc.Paths["/items"])["post"]
+	require.NotNil(t, create)
+	require.NotNil(t, create.RequestBody)
+	assert.Equal(t, "#/components/schemas/createItem", create.RequestBody.Content[contentTypeJSON].Schema.Ref)
+	assert.Contains(t, create.Responses, "201")
+
+	assert.Contains(t, *doc.Paths["/healthy"], "get")
+	assert.Equal(t, "health.delete", (*doc.Paths["/healthy"])["delete"].OperationID)
+
+	// explicit methods are not overwritten by routes without a method
+	assert.Same(t, create, (*doc.Paths["/items"])["post"])
+	assert.Equal(t, "any.get", (*doc.Paths["/items"])["get"].OperationID)
+	assert.Len(t, *doc.Paths["/items"], 5)
+
+	require.NotNil(t, doc.Components)
+	schema := doc.Components.Schemas["item"]
+	require.NotNil(t, schema)
+	assert.Equal(t, []string{"id", "name", "created"}, schema.Required)
+	assert.Equal(t, &Schema{Type: "string", Format: "date-time"}, schema.Properties["created"])
+	assert.Equal(t, &Schema{Type: "array", Items: &Schema{Type: "string"}}, schema.Properties["tags"])
+	assert.Equal(t, "#/components/schemas/item", schema.Properties["parent"].Ref)
+	assert.NotContains(t, schema.Properties, "internal")
+}
@@ -0,0 +1,199 @@
+// Copyright (c) 2026, Roel Schut. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package openapi
+
+import (
+	"encoding"
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schema is a JSON Schema object as used by OpenAPI 3.1.
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 any                `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+}
+
+var (
+	timeType            = reflect.TypeFor[time.Time]()
+	durationType        = reflect.TypeFor[time.Duration]()
+	rawMessageType      = reflect.TypeFor[json.RawMessage]()
+	jsonMarshalerType   = reflect.TypeFor[json.Marshaler]()
+	textMarshalerType   = reflect.TypeFor[encoding.TextMarshaler]()
+	emptyInterfaceType  = reflect.TypeFor[any]()
+	schemaRefPathPrefix = "#/components/schemas/"
+)
+
+// generator derives [Schema]s from types. Named struct types are added to
+// schemas and referenced.
+type generator struct {
+	schemas map[string]*Schema
+	names   map[reflect.Type]string
+}
+
+func newGenerator() *generator {
+	return &generator{
+		schemas: make(map[string]*Schema),
+		names:   make(map[reflect.Type]string),
+	}
+}
+
+func (g *generator) schema(typ reflect.Type) *Schema {
+	for typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+
+	switch typ {
+	case timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case durationType:
+		return &Schema{Type: "integer", Format: "int64"}
+	case rawMessageType, emptyInterfaceType:
+		return &Schema{}
+	}
+	if typ.Implements(jsonMarshalerType) || reflect.PointerTo(typ).Implements(jsonMarshalerType) {
+		return &Schema{}
+	}
+	if typ.Implements(textMarshalerType) || reflect.PointerTo(typ).Implements(textMarshalerType) {
+		return &Schema{Type: "string"}
+	}
+
+	switch typ.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if typ.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: g.schema(typ.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: g.schema(typ.Elem())}
+	case reflect.Struct:
+		return g.structSchema(typ)
+	default:
+		return &Schema{}
+	}
+}
+
+func (g *generator) structSchema(typ reflect.Type) *Schema {
+	name := schemaName(typ)
+	if name == "" {
+		return g.objectSchema(typ)
+	}
+	if n, ok := g.names[typ]; ok {
+		return &Schema{Ref: schemaRefPathPrefix + n}
+	}
+
+	// make name unique in case of equally named types from other packages
+	unique := name
+	for i := 2; g.schemas[unique] != nil; i++ {
+		unique = name + strconv.Itoa(i)
+	}
+
+	g.names[typ] = unique
+	// reserve the name before generating to support recursive types
+	g.schemas[unique] = &Schema{}
+	*g.schemas[unique] = *g.objectSchema(typ)
+	return &Schema{Ref: schemaRefPathPrefix + unique}
+}
+
+func (g *generator) objectSchema(typ reflect.Type) *Schema {
+	s := &Schema{
+		Type:       "object",
+		Properties: make(map[string]*Schema, typ.NumField()),
+	}
+	g.addFields(s, typ)
+	return s
+}
+
+func (g *generator) addFields(s *Schema, typ reflect.Type) {
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+
+		name, opts, _ := strings.Cut(tag, ",")
+		if field.Anonymous && name == "" {
+			ft := field.Type
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				g.addFields(s, ft)
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		s.Properties[name] = g.schema(field.Type)
+		if !strings.Contains(opts, "omitempty") && !strings.Contains(opts, "omitzero") &&
+			field.Type.Kind() != reflect.Pointer {
+			s.Required = append(s.Required, name)
+		}
+	}
+}
+
+func (g *generator) problemSchema() *Schema {
+	if _, ok := g.schemas[problemSchemaName]; !ok {
+		g.schemas[problemSchemaName] = &Schema{
+			Type: "object",
+			Properties: map[string]*Schema{
+				"type":       {Type: "string"},
+				"title":      {Type: "string"},
+				"status":     {Type: "integer"},
+				"detail":     {Type: "string"},
+				"instance":   {Type: "string"},
+				"trace_id":   {Type: "string"},
+				"request_id": {Type: "string"},
+			},
+		}
+	}
+	return &Schema{Ref: schemaRefPathPrefix + problemSchemaName}
+}
+
+// schemaName returns a name for the named type, which is usable as key in
+// [Components.Schemas].
+func schemaName(typ reflect.Type) string {
+	name := typ.Name()
+	if name == "" {
+		return ""
+	}
+
+	// strip type parameters of generic types, e.g. Page[pkg.Item] -> PageItem
+	if i := strings.IndexByte(name, '['); i >= 0 {
+		params := strings.Trim(name[i:], "[]")
+		name = name[:i]
+		for _, p := range strings.Split(params, ",") {
+			if j := strings.LastIndexByte(p, '.'); j >= 0 {
+				p = p[j+1:]
+			}
+			name += strings.TrimLeft(p, "*[]")
+		}
+	}
+	return name
+}
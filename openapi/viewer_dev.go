@@ -0,0 +1,28 @@
+// Copyright (c) 2026, Roel Schut. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build dev
+
+package openapi
+
+import (
+	_ "embed"
+	"html/template"
+	"net/http"
+)
+
+//go:embed viewer.html
+var viewerHTML string
+
+var viewerTmpl = template.Must(template.New("viewer").Parse(viewerHTML))
+
+// Viewer returns a [http.Handler] which serves a minimal HTML page that
+// renders the OpenAPI document served at specURL. It is only available in
+// dev builds, in other builds it returns nil.
+func Viewer(specURL string) http.Handler {
+	return http.HandlerFunc(func(wri http.ResponseWriter, _ *http.Request) {
+		wri.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_ = viewerTmpl.Execute(wri, specURL)
+	})
+}
@@ -0,0 +1,12 @@
+// Copyright (c) 2026, Roel Schut. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !dev
+
+package openapi
+
+import "net/http"
+
+// Viewer is only available in dev builds, in other builds it returns nil.
+func Viewer(string) http.Handler { return nil }
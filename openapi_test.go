@@ -0,0 +1,63 @@
+// Copyright (c) 2026, Roel Schut. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package webapp
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-pogo/serv"
+	"github.com/go-pogo/webapp/openapi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithOpenAPIRoute(t *testing.T) {
+	type user struct {
+		Name string `json:"name"`
+	}
+
+	base, err := New(
+		WithName("test"),
+		WithOpenAPIRoute("", openapi.Info{Version: "v1"}),
+		WithRoutesRegisterer(serv.RoutesRegistererFunc(func(rh serv.RouteHandler) {
+			rh.HandleRoute(WithRouteMeta(serv.Route{
+				Name:    "users.create",
+				Method:  http.MethodPost,
+				Pattern: "/users/{id}",
+				Handler: JSON(func(_ context.Context, in user) (user, error) {
+					return in, nil
+				}).WithStatus(http.StatusCreated),
+			}, RouteMeta{Summary: "Create user"}))
+		})),
+	)
+	require.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+	base.Server().Handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, OpenAPIPathPattern, nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var doc openapi.Document
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &doc))
+	assert.Equal(t, openapi.Info{Title: "test", Version: "v1"}, doc.Info)
+	require.Contains(t, doc.Paths, "/users/{id}")
+
+	op := (*doc.Paths["/users/{id}"])["post"]
+	require.NotNil(t, op)
+	assert.Equal(t, "Create user", op.Summary)
+	assert.Len(t, op.Parameters, 1)
+	assert.NotNil(t, op.RequestBody)
+	assert.Contains(t, op.Responses, "201")
+	assert.Contains(t, doc.Components.Schemas, "user")
+}
+
+func TestOpenAPIViewPattern(t *testing.T) {
+	assert.Equal(t, OpenAPIViewPathPattern, openAPIViewPattern(OpenAPIPathPattern))
+	assert.Equal(t, "api.example.com/docs/spec", openAPIViewPattern("api.example.com/docs/spec.json"))
+	assert.Equal(t, "/spec.html", openAPIViewPattern("/spec"))
+}
@@ -5,7 +5,11 @@
 package webapp
 
 import (
+	"crypto/tls"
 	"net/http"
+	"path"
+	"strings"
+	"time"
 
 	"github.com/go-pogo/buildinfo"
 	"github.com/go-pogo/easytls"
@@ -14,13 +18,36 @@ import (
 	"github.com/go-pogo/serv/accesslog"
 	"github.com/go-pogo/serv/response"
 	"github.com/go-pogo/telemetry"
-	semconv "go.opentelemetry.io/otel/semconv/v1.40.0"
+	"github.com/go-pogo/webapp/auth"
+	"github.com/go-pogo/webapp/logger"
+	"github.com/go-pogo/webapp/openapi"
+	"github.com/go-pogo/webapp/problem"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.opentelemetry.io/otel/attribute"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
 )
 
 const (
 	BuildInfoRoute   = buildinfo.MetricName
 	HealthCheckRoute = "healthcheck"
 	FaviconRoute     = "favicon"
+	RoutesRoute      = "routes"
+	MetricsRoute     = "metrics"
+	OpenAPIRoute     = "openapi"
+	OpenAPIViewRoute = "openapi.view"
+)
+
+const (
+	// RoutesPathPattern is the default path pattern of the [RoutesRoute].
+	RoutesPathPattern = "/routes"
+	// MetricsPathPattern is the path pattern of the [MetricsRoute].
+	MetricsPathPattern = "/metrics"
+	// OpenAPIPathPattern is the default path pattern of the [OpenAPIRoute].
+	OpenAPIPathPattern = "/openapi.json"
+	// OpenAPIViewPathPattern is the path pattern of the [OpenAPIViewRoute]
+	// when the [OpenAPIRoute] has the default [OpenAPIPathPattern].
+	OpenAPIViewPathPattern = "/openapi"
 )
 
 type ServerConfig struct {
@@ -38,6 +65,23 @@ type config struct {
 	server   ServerConfig
 	servOpts []serv.Option
 	logger   Logger
+	auth     auth.Authenticator
+	problems bool
+	renderer ErrorRenderer
+
+	hostCerts hostCertificates
+
+	telemetry      *telemetry.Config
+	resourceAttrs  []attribute.KeyValue
+	metrics        *prometheus.Registry
+	otelLogs       bool
+	logExporters   []sdklog.Exporter
+	runtimeMetrics bool
+	sampling       *SamplingConfig
+	baggage        []string
+	isolated       bool
+
+	healthChecks map[string]healthcheck.HealthChecker
 }
 
 func WithName(name string) Option {
@@ -51,6 +95,9 @@ func WithName(name string) Option {
 func WithLogger(log Logger) Option {
 	return func(base *Base, config *config) error {
 		base.router.log = log
+		base.router.depLog, _ = log.(logger.DeprecatedRouteLogger)
+		base.router.panicLog, _ = log.(problem.PanicLogger)
+		base.log = log
 		config.logger = log
 		return nil
 	}
@@ -63,12 +110,14 @@ func WithBuildInfo(bld *buildinfo.BuildInfo) Option {
 
 	return func(base *Base, config *config) error {
 		base.build = bld
-		base.router.HandleRoute(serv.Route{
+		base.router.HandleRoute(WithRouteMeta(serv.Route{
 			Name:    BuildInfoRoute,
 			Method:  http.MethodGet,
 			Pattern: buildinfo.PathPattern,
 			Handler: buildinfo.HTTPHandler(bld),
-		})
+		}, RouteMeta{
+			Summary: "Build information of the application",
+		}))
 		return nil
 	}
 }
@@ -95,28 +144,14 @@ func WithReadBuildInfo() Option {
 	}
 }
 
+// WithTelemetryConfig enables tracing and metrics. The telemetry providers are
+// built using conf once all options are applied. They are set as the global
+// providers, and the [Logger] set with [WithLogger] as global OTEL logger,
+// unless [WithIsolatedTelemetry] is used.
 func WithTelemetryConfig(conf telemetry.Config) Option {
 	return func(base *Base, config *config) error {
-		builder := telemetry.NewBuilder(conf).Global().WithDefaultExporter()
-		if base.build != nil {
-			if base.build.Version != "" {
-				builder.TracerProvider.WithAttributes(
-					semconv.ServiceVersion(base.build.Version),
-				)
-			}
-			builder.TracerProvider.WithBuildInfo(base.build.Internal())
-		}
-
-		var err error
-		base.telem, err = builder.Build()
-		if err != nil {
-			return err
-		}
-
+		config.telemetry = &conf
 		base.router.trace = true
-		if config.logger != nil {
-			config.logger.SetOTELLogger()
-		}
 		return nil
 	}
 }
@@ -142,26 +177,53 @@ func (c *config) withServerOpts(opts ...serv.Option) {
 	c.servOpts = append(c.servOpts, opts...)
 }
 
+// WithHealthChecker enables health checking using a [healthcheck.Checker]
+// created with opts, and registers the [HealthCheckRoute]. The durations of
+// the health checks of [healthcheck.HealthChecker]s registered using
+// [WithHealthCheck], [Base.Register] or the Register method of
+// [Base.HealthChecker] are measured. Those of the
+// [healthcheck.WithHealthChecker] options cannot be measured, use
+// [WithHealthCheck] instead.
 func WithHealthChecker(opts ...healthcheck.Option) Option {
 	return func(base *Base, config *config) error {
 		var err error
+		// health changes are recorded on the lifecycle span before they are
+		// logged
+		hl := &healthLogger{base: base}
 		if config.logger != nil {
-			pre := []healthcheck.Option{healthcheck.WithLogger(config.logger)}
-			opts = append(pre, opts...)
+			hl.next = config.logger
 		}
 
-		base.health, err = healthcheck.New(opts...)
+		pre := []healthcheck.Option{healthcheck.WithLogger(hl)}
+		base.health, err = healthcheck.New(append(pre, opts...)...)
 		if err != nil {
 			return err
 		}
 
-		base.health.Register(config.name, base)
-		base.router.HandleRoute(serv.Route{
+		base.Register(config.name, base)
+		base.router.HandleRoute(WithRouteMeta(serv.Route{
 			Name:    HealthCheckRoute,
 			Method:  http.MethodGet,
 			Pattern: healthcheck.PathPattern,
 			Handler: healthcheck.HTTPHandler(base.health),
-		})
+		}, RouteMeta{
+			Summary:   "Health status of the application",
+			Telemetry: RouteTelemetry{DisableTracing: true},
+		}))
+		return nil
+	}
+}
+
+// WithHealthCheck registers check using [Base.Register] once all options are
+// applied, so the durations of its health checks are measured. Like
+// [Base.Register], it does nothing when health checking is not enabled using
+// [WithHealthChecker].
+func WithHealthCheck(name string, check healthcheck.HealthChecker) Option {
+	return func(_ *Base, config *config) error {
+		if config.healthChecks == nil {
+			config.healthChecks = make(map[string]healthcheck.HealthChecker)
+		}
+		config.healthChecks[name] = check
 		return nil
 	}
 }
@@ -173,6 +235,37 @@ func WithRoutesRegisterer(rr serv.RoutesRegisterer) Option {
 	}
 }
 
+// WithGroup registers the routes of rr on a new [Group] with the provided
+// prefix and [GroupOption]s. See [Base.Group] for additional details.
+func WithGroup(prefix string, rr serv.RoutesRegisterer, opts ...GroupOption) Option {
+	return func(base *Base, _ *config) error {
+		rr.RegisterRoutes(base.Group(prefix, opts...))
+		return nil
+	}
+}
+
+// WithHost registers the routes of rr on a new host-scoped [Group] with the
+// provided [GroupOption]s. See [Base.Host] for additional details.
+func WithHost(host string, rr serv.RoutesRegisterer, opts ...GroupOption) Option {
+	return func(base *Base, _ *config) error {
+		rr.RegisterRoutes(base.Host(host, opts...))
+		return nil
+	}
+}
+
+// WithHostCertificate serves the TLS certificate loaded by cert to clients
+// which request host using SNI. Other clients are served the certificate of
+// [ServerConfig.TLS].
+func WithHostCertificate(host string, cert easytls.TLSCertificateLoader) Option {
+	return func(_ *Base, config *config) error {
+		if config.hostCerts == nil {
+			config.hostCerts = make(hostCertificates, 1)
+		}
+		config.hostCerts[strings.ToLower(host)] = cert
+		return nil
+	}
+}
+
 func WithNotFoundHandler(h http.Handler) Option {
 	return func(base *Base, _ *config) error {
 		base.router.WithNotFoundHandler(h)
@@ -180,18 +273,193 @@ func WithNotFoundHandler(h http.Handler) Option {
 	}
 }
 
+// WithAuthenticator authenticates all requests to the server using the
+// provided [auth.Authenticator]. Use [auth.Handler] to authenticate requests
+// to individual routes instead.
+func WithAuthenticator(a auth.Authenticator) Option {
+	return func(_ *Base, config *config) error {
+		config.auth = a
+		return nil
+	}
+}
+
+// WithProblemDetails responds to requests for unknown routes and requests
+// which cause a panic with problem details responses as described in
+// RFC 9457. Recovered panics are logged when the [Logger] set with
+// [WithLogger] implements [problem.PanicLogger]. See package [problem] for
+// additional details.
+func WithProblemDetails() Option {
+	return func(base *Base, config *config) error {
+		config.problems = true
+		base.router.WithNotFoundHandler(problem.NotFoundHandler())
+		base.router.withMethodNotAllowedHandler(problem.MethodNotAllowedHandler())
+		return nil
+	}
+}
+
+// WithMethodNotAllowedHandler sets the handler which responds to requests that
+// match a route's path but not its method. The "Allow" header is set before h
+// is called.
+func WithMethodNotAllowedHandler(h http.Handler) Option {
+	return func(base *Base, _ *config) error {
+		base.router.withMethodNotAllowedHandler(h)
+		return nil
+	}
+}
+
+// WithErrorRenderer sets the [ErrorRenderer] used by [HandleError] to render
+// errors returned by a [HandlerFunc].
+func WithErrorRenderer(r ErrorRenderer) Option {
+	return func(_ *Base, config *config) error {
+		config.renderer = r
+		return nil
+	}
+}
+
 func WithIgnoreFaviconRoute() Option {
 	return func(base *Base, _ *config) error {
-		base.router.HandleRoute(serv.Route{
+		base.router.HandleRoute(WithRouteMeta(serv.Route{
 			Name:    FaviconRoute,
 			Method:  http.MethodGet,
 			Pattern: "/favicon.ico",
 			Handler: accesslog.IgnoreHandler(response.NoContentHandler()),
-		})
+		}, RouteMeta{
+			Summary:   "Empty favicon response",
+			Telemetry: RouteTelemetry{DisableTracing: true},
+		}))
+		return nil
+	}
+}
+
+// WithRoutesRoute registers the [RoutesRoute] which lists all registered
+// routes as JSON. The route's pattern defaults to [RoutesPathPattern] when
+// pattern is empty.
+func WithRoutesRoute(pattern string) Option {
+	if pattern == "" {
+		pattern = RoutesPathPattern
+	}
+
+	return func(base *Base, _ *config) error {
+		base.router.HandleRoute(WithRouteMeta(serv.Route{
+			Name:    RoutesRoute,
+			Method:  http.MethodGet,
+			Pattern: pattern,
+			Handler: RoutesHTTPHandler(base.Routes),
+		}, RouteMeta{
+			Summary: "List of all registered routes",
+		}))
+		return nil
+	}
+}
+
+// WithMetricsRoute registers the [MetricsRoute] which serves the metrics of the
+// [telemetry.Telemetry]'s MeterProvider in Prometheus text format, or
+// OpenMetrics format when requested by the scraper. Requests to the route are
+// not logged in the access log and are not traced. It requires
+// [WithTelemetryConfig], [New] returns an error matching
+// [ErrMetricsWithoutTelemetry] otherwise, or [ErrMetricsWithTelemetry] when
+// the telemetry is provided using [WithTelemetry].
+func WithMetricsRoute() Option {
+	return func(base *Base, config *config) error {
+		if config.metrics == nil {
+			config.metrics = prometheus.NewRegistry()
+		}
+
+		base.router.HandleRoute(WithRouteMeta(serv.Route{
+			Name:    MetricsRoute,
+			Method:  http.MethodGet,
+			Pattern: MetricsPathPattern,
+			Handler: accesslog.IgnoreHandler(promhttp.HandlerFor(config.metrics, promhttp.HandlerOpts{
+				EnableOpenMetrics: true,
+			})),
+		}, RouteMeta{
+			Summary:   "Prometheus metrics",
+			Telemetry: RouteTelemetry{DisableTracing: true},
+		}))
+		return nil
+	}
+}
+
+// WithOpenAPIRoute registers the [OpenAPIRoute] which serves an OpenAPI 3.1
+// document, generated from all registered routes, as JSON. The route's
+// pattern defaults to [OpenAPIPathPattern] when pattern is empty. In dev builds
+// the [OpenAPIViewRoute] is registered as well, which serves a HTML page that
+// renders the document. Its pattern is pattern without extension, or pattern
+// with a ".html" extension when it has none.
+func WithOpenAPIRoute(pattern string, info openapi.Info) Option {
+	if pattern == "" {
+		pattern = OpenAPIPathPattern
+	}
+
+	return func(base *Base, config *config) error {
+		base.router.HandleRoute(WithRouteMeta(serv.Route{
+			Name:    OpenAPIRoute,
+			Method:  http.MethodGet,
+			Pattern: pattern,
+			Handler: http.HandlerFunc(func(wri http.ResponseWriter, req *http.Request) {
+				// name and build info may be set by options applied later on
+				info := info
+				if info.Title == "" {
+					info.Title = config.name
+				}
+				if info.Version == "" && base.build != nil {
+					info.Version = base.build.Version
+				}
+				OpenAPIHTTPHandler(info, base.Routes).ServeHTTP(wri, req)
+			}),
+		}, RouteMeta{
+			Summary: "OpenAPI document of all registered routes",
+		}))
+
+		specURL, err := base.URLFor(OpenAPIRoute)
+		if err != nil {
+			return err
+		}
+		if viewer := openapi.Viewer(specURL); viewer != nil {
+			base.router.HandleRoute(WithRouteMeta(serv.Route{
+				Name:    OpenAPIViewRoute,
+				Method:  http.MethodGet,
+				Pattern: openAPIViewPattern(pattern),
+				Handler: viewer,
+			}, RouteMeta{
+				Summary: "OpenAPI document viewer",
+			}))
+		}
+		return nil
+	}
+}
+
+func openAPIViewPattern(pattern string) string {
+	if ext := path.Ext(pattern); ext != "" {
+		return strings.TrimSuffix(pattern, ext)
+	}
+	return pattern + ".html"
+}
+
+// WithRouteTimeout sets the default timeout of all routes. A route's
+// [RouteMeta.Timeout] takes precedence over this default. Streaming routes
+// (see [RouteMeta.Streaming]) are never timed out. When a route's handler does
+// not finish in time, a 503 response is sent, or the response is aborted when
+// its headers are already sent. Requests canceled by the client receive no
+// response and are logged with a 499 status code. Panics of handlers after
+// their timeout are logged when the [Logger] set with [WithLogger] implements
+// [problem.PanicLogger].
+func WithRouteTimeout(timeout time.Duration) Option {
+	return func(base *Base, _ *config) error {
+		base.router.timeout.Store(int64(timeout))
 		return nil
 	}
 }
 
+// serviceName returns the name of the service as configured with
+// [WithTelemetryConfig], or the name set with [WithName].
+func (c *config) serviceName() string {
+	if c.telemetry != nil && c.telemetry.ServiceName != "" {
+		return c.telemetry.ServiceName
+	}
+	return c.name
+}
+
 func (c *config) servLogger() serv.Logger {
 	if c.logger == nil {
 		return serv.NopLogger()
@@ -205,3 +473,29 @@ func (c *config) accessLogger() accesslog.Logger {
 	}
 	return c.logger
 }
+
+var _ easytls.Option = (hostCertificates)(nil)
+
+// hostCertificates selects a TLS certificate by the server name requested by
+// the client using SNI.
+type hostCertificates map[string]easytls.TLSCertificateLoader
+
+// ApplyTo is part of the [easytls.Option] interface.
+func (hc hostCertificates) ApplyTo(conf *tls.Config, target easytls.Target) error {
+	if conf == nil || target != easytls.TargetServer || len(hc) == 0 {
+		return nil
+	}
+
+	fallback := conf.GetCertificate
+	conf.GetCertificate = func(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+		if cert, ok := hc[strings.ToLower(hello.ServerName)]; ok {
+			return easytls.GetCertificate(cert)(hello)
+		}
+		if fallback != nil {
+			return fallback(hello)
+		}
+		// use certificates from tls.Config.Certificates
+		return nil, nil
+	}
+	return nil
+}
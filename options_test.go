@@ -5,13 +5,18 @@
 package webapp
 
 import (
+	"context"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 
 	"github.com/go-pogo/serv"
+	"github.com/go-pogo/telemetry"
+	"github.com/go-pogo/webapp/problem"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 )
 
 func TestWithName(t *testing.T) {
@@ -52,3 +57,55 @@ func TestWithIgnoreFaviconRoute(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
 }
+
+func TestWithProblemDetails(t *testing.T) {
+	base, err := New(WithProblemDetails())
+	assert.NoError(t, err)
+
+	srv := httptest.NewServer(base.Server().Handler)
+	defer srv.Close()
+
+	resp, err := srv.Client().Get(srv.URL + "/does-not-exist")
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	assert.Equal(t, problem.ContentType, resp.Header.Get("Content-Type"))
+}
+
+func TestWithMetricsRoute(t *testing.T) {
+	// metrics route is registered before telemetry is configured
+	base, err := New(WithMetricsRoute(), WithTelemetryConfig(telemetry.Config{}))
+	require.NoError(t, err)
+
+	counter, err := base.Telemetry().MeterProvider().Meter("test").Int64Counter("test.counter")
+	require.NoError(t, err)
+	counter.Add(context.Background(), 3)
+
+	t.Run("prometheus", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		base.Server().Handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, MetricsPathPattern, nil))
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Header().Get("Content-Type"), "text/plain")
+		assert.Contains(t, rec.Body.String(), "test_counter_total")
+	})
+	t.Run("without telemetry", func(t *testing.T) {
+		_, err := New(WithMetricsRoute())
+		assert.ErrorIs(t, err, ErrMetricsWithoutTelemetry)
+	})
+	t.Run("with provided telemetry", func(t *testing.T) {
+		_, err := New(
+			WithTelemetry(telemetry.New(sdkmetric.NewMeterProvider(), sdktrace.NewTracerProvider())),
+			WithMetricsRoute(),
+		)
+		assert.ErrorIs(t, err, ErrMetricsWithTelemetry)
+	})
+	t.Run("openmetrics", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, MetricsPathPattern, nil)
+		req.Header.Set("Accept", "application/openmetrics-text; version=1.0.0")
+
+		rec := httptest.NewRecorder()
+		base.Server().Handler.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Header().Get("Content-Type"), "application/openmetrics-text")
+		assert.Contains(t, rec.Body.String(), "# EOF")
+	})
+}
@@ -0,0 +1,114 @@
+// Copyright (c) 2026, Roel Schut. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package problem
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"runtime/debug"
+	"strings"
+
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.40.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// NotFoundHandler responds with a [http.StatusNotFound] problem details
+// response.
+func NotFoundHandler() http.Handler {
+	return http.HandlerFunc(func(wri http.ResponseWriter, req *http.Request) {
+		_ = Write(wri, req, New(http.StatusNotFound, ""))
+	})
+}
+
+// MethodNotAllowedHandler responds with a [http.StatusMethodNotAllowed]
+// problem details response. The Allow header is set with the allowed methods.
+func MethodNotAllowedHandler(allow ...string) http.Handler {
+	allowed := strings.Join(allow, ", ")
+	return http.HandlerFunc(func(wri http.ResponseWriter, req *http.Request) {
+		if allowed != "" {
+			wri.Header().Set("Allow", allowed)
+		}
+		_ = Write(wri, req, New(http.StatusMethodNotAllowed, ""))
+	})
+}
+
+// PanicLogger logs panics recovered by [Recover].
+type PanicLogger interface {
+	LogPanic(ctx context.Context, v any, stack []byte, req *http.Request)
+}
+
+// Recover recovers from panics in next and responds with a
+// [http.StatusInternalServerError] problem details response. The panic's value
+// and stack are recorded as error on the request's span and logged using log,
+// when not nil. When the response's headers are already sent, the incomplete
+// response is aborted by panicking with [http.ErrAbortHandler] instead. A
+// panic with [http.ErrAbortHandler] is not recovered.
+func Recover(log PanicLogger, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(wri http.ResponseWriter, req *http.Request) {
+		rw := &recoverResponseWriter{ResponseWriter: wri}
+		defer func() {
+			v := recover()
+			if v == nil {
+				return
+			}
+			if v == http.ErrAbortHandler {
+				panic(v)
+			}
+
+			ctx := req.Context()
+			stack := debug.Stack()
+			err, ok := v.(error)
+			if !ok {
+				err = fmt.Errorf("panic: %v", v)
+			}
+
+			span := trace.SpanFromContext(ctx)
+			span.RecordError(err, trace.WithAttributes(
+				semconv.ExceptionStacktrace(string(stack)),
+			))
+			span.SetStatus(codes.Error, err.Error())
+			if log != nil {
+				log.LogPanic(ctx, v, stack, req)
+			}
+			if rw.wroteHeader {
+				// abort the incomplete response, so the client does not
+				// mistake it for a complete response
+				panic(http.ErrAbortHandler)
+			}
+			_ = Write(wri, req, New(http.StatusInternalServerError, ""))
+		}()
+
+		next.ServeHTTP(rw, req)
+	})
+}
+
+// recoverResponseWriter records whether the response's headers are sent.
+type recoverResponseWriter struct {
+	http.ResponseWriter
+	wroteHeader bool
+}
+
+func (rw *recoverResponseWriter) WriteHeader(code int) {
+	if code >= 200 || code == http.StatusSwitchingProtocols {
+		// informational responses do not send the final headers
+		rw.wroteHeader = true
+	}
+	rw.ResponseWriter.WriteHeader(code)
+}
+
+func (rw *recoverResponseWriter) Write(p []byte) (int, error) {
+	rw.wroteHeader = true
+	return rw.ResponseWriter.Write(p)
+}
+
+// Flush is part of the [http.Flusher] interface.
+func (rw *recoverResponseWriter) Flush() {
+	rw.wroteHeader = true
+	_ = http.NewResponseController(rw.ResponseWriter).Flush()
+}
+
+func (rw *recoverResponseWriter) Unwrap() http.ResponseWriter { return rw.ResponseWriter }
@@ -0,0 +1,110 @@
+// Copyright (c) 2026, Roel Schut. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package problem
+
+import (
+	"context"
+	"net/http"
+	"sync"
+
+	"github.com/go-pogo/errors"
+)
+
+// DefaultMapper is the default [Mapper] used by the package level functions.
+var DefaultMapper = NewMapper()
+
+// Register registers target with status on [DefaultMapper].
+func Register(target error, status int) { DefaultMapper.Register(target, status) }
+
+// Extender is an optional interface which may be implemented by errors to add
+// additional members to their [Details].
+type Extender interface {
+	error
+	ExtendProblem(d *Details)
+}
+
+// Mapper maps errors to [Details]. Errors are matched against the registered
+// targets using [errors.Is], in order of registration.
+type Mapper struct {
+	mut   sync.RWMutex
+	rules []rule
+}
+
+type rule struct {
+	target error
+	status int
+	typ    string
+}
+
+// NewMapper returns a new [Mapper] which maps [context.DeadlineExceeded] and
+// [http.ErrHandlerTimeout] to [http.StatusServiceUnavailable].
+func NewMapper() *Mapper {
+	var m Mapper
+	m.Register(context.DeadlineExceeded, http.StatusServiceUnavailable)
+	m.Register(http.ErrHandlerTimeout, http.StatusServiceUnavailable)
+	return &m
+}
+
+// Register maps errors matching target to the provided status code. Targets
+// are typically sentinel errors, such as an [errors.Msg].
+func (m *Mapper) Register(target error, status int) *Mapper {
+	return m.RegisterType(target, status, "")
+}
+
+// RegisterType maps errors matching target to the provided status code and
+// problem type URI.
+func (m *Mapper) RegisterType(target error, status int, typ string) *Mapper {
+	m.mut.Lock()
+	m.rules = append(m.rules, rule{
+		target: target,
+		status: status,
+		typ:    typ,
+	})
+	m.mut.Unlock()
+	return m
+}
+
+// Details returns the [Details] of err. Its status code is taken from the
+// first registered target matching err. When none match, the status code
+// defaults to [errors.GetStatusCodeOr] with [http.StatusInternalServerError]
+// as fallback. The error's message is only used as [Details.Detail] when err
+// matches a registered target or has a status code lower than 500, so
+// internal errors are not exposed. When err implements [Extender], it is
+// called to extend the [Details].
+func (m *Mapper) Details(err error) *Details {
+	if err == nil {
+		return New(http.StatusInternalServerError, "")
+	}
+
+	d := m.details(err)
+	var ext Extender
+	if errors.As(err, &ext) {
+		ext.ExtendProblem(d)
+	}
+	return d
+}
+
+func (m *Mapper) details(err error) *Details {
+	m.mut.RLock()
+	defer m.mut.RUnlock()
+
+	for _, r := range m.rules {
+		if !errors.Is(err, r.target) {
+			continue
+		}
+
+		d := New(r.status, err.Error())
+		if r.typ != "" {
+			d.Type = r.typ
+		}
+		return d
+	}
+
+	status := errors.GetStatusCodeOr(err, http.StatusInternalServerError)
+	if status >= http.StatusInternalServerError {
+		return New(status, "")
+	}
+	return New(status, err.Error())
+}
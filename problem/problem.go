@@ -0,0 +1,140 @@
+// Copyright (c) 2026, Roel Schut. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package problem renders error responses as problem details according to
+// RFC 9457.
+package problem
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-pogo/errors"
+	"github.com/go-pogo/serv"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ContentType is the media type of a problem details response.
+const ContentType = "application/problem+json"
+
+// DefaultType is the default value of [Details.Type], which indicates the
+// problem has no additional semantics beyond that of the HTTP status code.
+const DefaultType = "about:blank"
+
+var _ json.Marshaler = (*Details)(nil)
+
+// Details is a problem details object as described in RFC 9457.
+type Details struct {
+	// Type is a URI reference that identifies the problem type.
+	Type string
+	// Title is a short, human-readable summary of the problem type.
+	Title string
+	// Status is the HTTP status code.
+	Status int
+	// Detail is a human-readable explanation specific to this occurrence of
+	// the problem.
+	Detail string
+	// Instance is a URI reference that identifies the specific occurrence of
+	// the problem.
+	Instance string
+	// TraceID is the id of the trace the request is part of.
+	TraceID string
+	// RequestID is the id of the request.
+	RequestID string
+	// Extensions contains additional members of the problem details object.
+	Extensions map[string]any
+}
+
+// New returns new [Details] with the provided status code and the status
+// code's text as title.
+func New(status int, detail string) *Details {
+	return &Details{
+		Type:   DefaultType,
+		Title:  http.StatusText(status),
+		Status: status,
+		Detail: detail,
+	}
+}
+
+// With adds the key and value to [Details.Extensions].
+func (d *Details) With(key string, val any) *Details {
+	if d.Extensions == nil {
+		d.Extensions = make(map[string]any, 2)
+	}
+	d.Extensions[key] = val
+	return d
+}
+
+// MarshalJSON is part of the [json.Marshaler] interface. Extension members are
+// added to the same object as the standard members.
+func (d *Details) MarshalJSON() ([]byte, error) {
+	m := make(map[string]any, len(d.Extensions)+7)
+	for k, v := range d.Extensions {
+		m[k] = v
+	}
+
+	typ := d.Type
+	if typ == "" {
+		typ = DefaultType
+	}
+	m["type"] = typ
+
+	if d.Title != "" {
+		m["title"] = d.Title
+	}
+	if d.Status != 0 {
+		m["status"] = d.Status
+	}
+	if d.Detail != "" {
+		m["detail"] = d.Detail
+	}
+	if d.Instance != "" {
+		m["instance"] = d.Instance
+	}
+	if d.TraceID != "" {
+		m["trace_id"] = d.TraceID
+	}
+	if d.RequestID != "" {
+		m["request_id"] = d.RequestID
+	}
+	return json.Marshal(m)
+}
+
+// Write writes the [Details] as problem details response. Its trace id and
+// request id are set from the request's context when empty.
+func Write(wri http.ResponseWriter, req *http.Request, d *Details) error {
+	if d.Status == 0 {
+		d.Status = http.StatusInternalServerError
+	}
+	if d.Title == "" {
+		d.Title = http.StatusText(d.Status)
+	}
+	if req != nil {
+		ctx := req.Context()
+		if d.TraceID == "" {
+			if sc := trace.SpanContextFromContext(ctx); sc.HasTraceID() {
+				d.TraceID = sc.TraceID().String()
+			}
+		}
+		if d.RequestID == "" {
+			d.RequestID = serv.RequestID(ctx)
+		}
+	}
+
+	b, err := json.Marshal(d)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	wri.Header().Set("Content-Type", ContentType)
+	wri.Header().Set("X-Content-Type-Options", "nosniff")
+	wri.WriteHeader(d.Status)
+	_, err = wri.Write(b)
+	return errors.WithStack(err)
+}
+
+// WriteError writes err as problem details response using [DefaultMapper].
+func WriteError(wri http.ResponseWriter, req *http.Request, err error) error {
+	return Write(wri, req, DefaultMapper.Details(err))
+}
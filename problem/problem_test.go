@@ -0,0 +1,147 @@
+// Copyright (c) 2026, Roel Schut. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package problem
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-pogo/errors"
+	"github.com/go-pogo/serv"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestMapper_Details(t *testing.T) {
+	const errNotFound errors.Msg = "item not found"
+	mapper := NewMapper().RegisterType(errNotFound, http.StatusNotFound, "https://example.com/not-found")
+
+	tests := map[string]struct {
+		err        error
+		wantStatus int
+		wantType   string
+		wantDetail string
+	}{
+		"registered": {
+			err:        errors.Wrap(errors.New(errNotFound), "wrapped"),
+			wantStatus: http.StatusNotFound,
+			wantType:   "https://example.com/not-found",
+			wantDetail: "wrapped",
+		},
+		"deadline exceeded": {
+			err:        errors.WithStack(context.DeadlineExceeded),
+			wantStatus: http.StatusServiceUnavailable,
+			wantType:   DefaultType,
+			wantDetail: context.DeadlineExceeded.Error(),
+		},
+		"status code": {
+			err:        errors.WithStatusCode(errors.New("bad input"), http.StatusBadRequest),
+			wantStatus: http.StatusBadRequest,
+			wantType:   DefaultType,
+			wantDetail: "bad input",
+		},
+		"internal": {
+			err:        errors.New("secret internals"),
+			wantStatus: http.StatusInternalServerError,
+			wantType:   DefaultType,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			have := mapper.Details(tc.err)
+			assert.Equal(t, tc.wantStatus, have.Status)
+			assert.Equal(t, tc.wantType, have.Type)
+			assert.Equal(t, tc.wantDetail, have.Detail)
+			assert.Equal(t, http.StatusText(tc.wantStatus), have.Title)
+		})
+	}
+}
+
+func TestWrite(t *testing.T) {
+	const requestID = "some-request-id"
+	ctx := serv.ContextWithInfo(context.Background(), serv.Info{RequestID: requestID})
+	req := httptest.NewRequestWithContext(ctx, http.MethodGet, "/", nil)
+	rec := httptest.NewRecorder()
+
+	require.NoError(t, Write(rec, req, New(http.StatusConflict, "conflict").With("foo", "bar")))
+	assert.Equal(t, http.StatusConflict, rec.Code)
+	assert.Equal(t, ContentType, rec.Header().Get("Content-Type"))
+
+	var have map[string]any
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &have))
+	assert.Equal(t, map[string]any{
+		"type":       DefaultType,
+		"title":      http.StatusText(http.StatusConflict),
+		"status":     float64(http.StatusConflict),
+		"detail":     "conflict",
+		"request_id": requestID,
+		"foo":        "bar",
+	}, have)
+}
+
+type panicLogger struct {
+	values []any
+	stack  []byte
+}
+
+func (l *panicLogger) LogPanic(_ context.Context, v any, stack []byte, _ *http.Request) {
+	l.values = append(l.values, v)
+	l.stack = stack
+}
+
+func TestRecover(t *testing.T) {
+	exp := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exp))
+	ctx, span := tp.Tracer("test").Start(context.Background(), "request")
+
+	var log panicLogger
+	rec := httptest.NewRecorder()
+	Recover(&log, http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		panic("oops")
+	})).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil).WithContext(ctx))
+	span.End()
+
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	assert.Equal(t, ContentType, rec.Header().Get("Content-Type"))
+	assert.Equal(t, []any{"oops"}, log.values)
+	assert.Contains(t, string(log.stack), "TestRecover")
+
+	spans := exp.GetSpans()
+	require.Len(t, spans, 1)
+	assert.Equal(t, codes.Error, spans[0].Status.Code)
+	require.Len(t, spans[0].Events, 1)
+	assert.Equal(t, "exception", spans[0].Events[0].Name)
+
+	t.Run("headers sent", func(t *testing.T) {
+		var log panicLogger
+		srv := httptest.NewServer(Recover(&log, http.HandlerFunc(func(wri http.ResponseWriter, _ *http.Request) {
+			wri.WriteHeader(http.StatusAccepted)
+			_, _ = wri.Write([]byte("partial"))
+			wri.(http.Flusher).Flush()
+			panic("oops")
+		})))
+		defer srv.Close()
+
+		res, err := srv.Client().Get(srv.URL)
+		require.NoError(t, err)
+		defer res.Body.Close()
+
+		assert.Equal(t, http.StatusAccepted, res.StatusCode)
+		body, err := io.ReadAll(res.Body)
+		assert.Error(t, err, "connection must be aborted")
+		assert.Equal(t, "partial", string(body))
+
+		srv.Close() // waits for the handler to finish
+		assert.Equal(t, []any{"oops"}, log.values)
+	})
+}
@@ -0,0 +1,156 @@
+// Copyright (c) 2026, Roel Schut. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package webapp
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-pogo/errors"
+	"go.opentelemetry.io/contrib/propagators/b3"
+	"go.opentelemetry.io/contrib/propagators/jaeger"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/baggage"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const ErrUnknownPropagator errors.Msg = "unknown propagator"
+
+// BaggageAttributePrefix prefixes the name of a baggage member to form its
+// attribute key, so baggage members cannot overwrite other attributes.
+const BaggageAttributePrefix = "baggage."
+
+// UnknownPropagatorError is the error returned by [NewPropagator] when a name
+// does not match a known propagator. It wraps [ErrUnknownPropagator].
+type UnknownPropagatorError struct {
+	Name string
+}
+
+func (e *UnknownPropagatorError) Error() string {
+	return ErrUnknownPropagator.Error() + " " + strconv.Quote(e.Name)
+}
+
+func (e *UnknownPropagatorError) Unwrap() error { return ErrUnknownPropagator }
+
+// PropagationConfig configures how trace context and baggage are propagated,
+// see [WithPropagation].
+type PropagationConfig struct {
+	// Propagators used to extract trace context and baggage from incoming
+	// requests and inject them into outbound requests. Valid values are
+	// tracecontext, baggage, b3, b3multi, jaeger and none.
+	Propagators []string `env:"OTEL_PROPAGATORS,noprefix" default:"tracecontext,baggage" description:"Comma separated list of propagators: tracecontext, baggage, b3, b3multi, jaeger or none"`
+	// Baggage lists the names of the baggage members which are added as
+	// attributes to the request's span and as fields to log lines. Their names
+	// are prefixed with [BaggageAttributePrefix] and
+	// [logger.BaggageFieldPrefix].
+	Baggage []string `env:"BAGGAGE_ALLOWLIST" description:"Comma separated list of baggage members which are added to spans and log lines"`
+}
+
+// NewPropagator returns a composite [propagation.TextMapPropagator] of the
+// propagators with the provided names, see [PropagationConfig].Propagators.
+// The W3C tracecontext and baggage propagators are returned when no names are
+// provided.
+func NewPropagator(names ...string) (propagation.TextMapPropagator, error) {
+	if len(names) == 0 {
+		names = []string{"tracecontext", "baggage"}
+	}
+
+	props := make([]propagation.TextMapPropagator, 0, len(names))
+	for _, name := range names {
+		switch strings.ToLower(strings.TrimSpace(name)) {
+		case "tracecontext":
+			props = append(props, propagation.TraceContext{})
+		case "baggage":
+			props = append(props, propagation.Baggage{})
+		case "b3":
+			props = append(props, b3.New(b3.WithInjectEncoding(b3.B3SingleHeader)))
+		case "b3multi":
+			props = append(props, b3.New(b3.WithInjectEncoding(b3.B3MultipleHeader)))
+		case "jaeger":
+			props = append(props, jaeger.Jaeger{})
+		case "none", "":
+			continue
+		default:
+			return nil, errors.WithStack(&UnknownPropagatorError{Name: name})
+		}
+	}
+	return propagation.NewCompositeTextMapPropagator(props...), nil
+}
+
+// WithPropagation propagates trace context and baggage of incoming requests,
+// and outbound requests made by clients from [Base.HTTPClient], using the
+// propagators listed in conf. This also applies when telemetry is not
+// configured, in which case incoming requests are not traced but their trace
+// context and baggage are still extracted and injected into outbound
+// requests. When telemetry is configured using [WithTelemetryConfig], the
+// propagator is also set as global TextMapPropagator, unless
+// [WithIsolatedTelemetry] is used. The baggage members listed in conf are
+// added as attributes to the request's span and, when the [Logger] set with
+// [WithLogger] implements [logger.BaggageFieldsSetter], as fields to log
+// lines.
+func WithPropagation(conf PropagationConfig) Option {
+	return func(base *Base, config *config) error {
+		prop, err := NewPropagator(conf.Propagators...)
+		if err != nil {
+			return err
+		}
+
+		base.propagator = prop
+		config.baggage = conf.Baggage
+		return nil
+	}
+}
+
+// addBaggageAttributes adds the values of the listed baggage members of the
+// request's context as attributes to its span, with their names prefixed with
+// [BaggageAttributePrefix].
+func addBaggageAttributes(members []string, next http.Handler) http.Handler {
+	keys := make([]attribute.Key, len(members))
+	for i, name := range members {
+		keys[i] = attribute.Key(BaggageAttributePrefix + name)
+	}
+
+	return http.HandlerFunc(func(wri http.ResponseWriter, req *http.Request) {
+		ctx := req.Context()
+		if bag := baggage.FromContext(ctx); bag.Len() != 0 {
+			attrs := make([]attribute.KeyValue, 0, len(members))
+			for i, name := range members {
+				if m := bag.Member(name); m.Key() != "" {
+					attrs = append(attrs, keys[i].String(m.Value()))
+				}
+			}
+			trace.SpanFromContext(ctx).SetAttributes(attrs...)
+		}
+		next.ServeHTTP(wri, req)
+	})
+}
+
+// extractPropagation extracts the trace context and baggage of incoming
+// requests using prop. It is used when requests are not traced, otherwise
+// [otelhttp.NewHandler] extracts them.
+func extractPropagation(prop propagation.TextMapPropagator, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(wri http.ResponseWriter, req *http.Request) {
+		ctx := prop.Extract(req.Context(), propagation.HeaderCarrier(req.Header))
+		next.ServeHTTP(wri, req.WithContext(ctx))
+	})
+}
+
+var _ http.RoundTripper = (*propagationTransport)(nil)
+
+// propagationTransport injects the trace context and baggage of a request's
+// context into its headers. It is used when requests are not traced,
+// otherwise [otelhttp.NewTransport] injects them.
+type propagationTransport struct {
+	prop propagation.TextMapPropagator
+	next http.RoundTripper
+}
+
+func (t *propagationTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	t.prop.Inject(req.Context(), propagation.HeaderCarrier(req.Header))
+	return t.next.RoundTrip(req)
+}
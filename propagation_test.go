@@ -0,0 +1,120 @@
+// Copyright (c) 2026, Roel Schut. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package webapp
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-pogo/serv"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestNewPropagator(t *testing.T) {
+	tests := map[string]struct {
+		names []string
+		want  []string
+	}{
+		"default": {
+			want: []string{"traceparent", "tracestate", "baggage"},
+		},
+		"b3": {
+			names: []string{"b3"},
+			want:  []string{"b3"},
+		},
+		"b3multi": {
+			names: []string{"b3multi"},
+			want:  []string{"x-b3-traceid", "x-b3-spanid", "x-b3-sampled", "x-b3-flags"},
+		},
+		"jaeger and baggage": {
+			names: []string{"jaeger", " Baggage "},
+			want:  []string{"uber-trace-id", "baggage"},
+		},
+		"none": {
+			names: []string{"none"},
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			prop, err := NewPropagator(tc.names...)
+			require.NoError(t, err)
+			assert.ElementsMatch(t, tc.want, prop.Fields())
+		})
+	}
+
+	t.Run("unknown", func(t *testing.T) {
+		_, err := NewPropagator("tracecontext", "zipkin")
+		assert.ErrorIs(t, err, ErrUnknownPropagator)
+		assert.EqualError(t, err, `unknown propagator "zipkin"`)
+	})
+}
+
+func TestWithPropagation(t *testing.T) {
+	exp := tracetest.NewInMemoryExporter()
+	base, err := New(
+		withTestTracing(exp),
+		WithPropagation(PropagationConfig{
+			Propagators: []string{"b3multi", "baggage"},
+			Baggage:     []string{"tenant"},
+		}),
+		WithRoutesRegisterer(serv.RoutesRegistererFunc(func(rh serv.RouteHandler) {
+			rh.HandleRoute(serv.Route{
+				Name:    "item",
+				Method:  http.MethodGet,
+				Pattern: "/item",
+				Handler: http.NotFoundHandler(),
+			})
+		})),
+	)
+	require.NoError(t, err)
+	exp.Reset()
+
+	req := httptest.NewRequest(http.MethodGet, "/item", nil)
+	req.Header.Set("X-B3-TraceId", "0102030405060708090a0b0c0d0e0f10")
+	req.Header.Set("X-B3-SpanId", "0102030405060708")
+	req.Header.Set("X-B3-Sampled", "1")
+	req.Header.Set("Baggage", "tenant=acme,secret=foo")
+	base.Server().Handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	spans := exp.GetSpans()
+	require.Len(t, spans, 1)
+	assert.Equal(t, "0102030405060708090a0b0c0d0e0f10", spans[0].SpanContext.TraceID().String())
+	assert.Equal(t, "0102030405060708", spans[0].Parent.SpanID().String())
+	assert.Contains(t, spans[0].Attributes, attribute.String("baggage.tenant", "acme"))
+	assert.NotContains(t, spans[0].Attributes, attribute.String("baggage.secret", "foo"))
+
+	t.Run("without telemetry", func(t *testing.T) {
+		var outbound http.Header
+		srv := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, req *http.Request) {
+			outbound = req.Header
+		}))
+		defer srv.Close()
+
+		base, err := New(WithPropagation(PropagationConfig{}))
+		require.NoError(t, err)
+		client := base.HTTPClient("test", ClientConfig{})
+
+		base.router.HandleFunc("/proxy", func(_ http.ResponseWriter, req *http.Request) {
+			out, err := http.NewRequestWithContext(req.Context(), http.MethodGet, srv.URL, nil)
+			require.NoError(t, err)
+			resp, err := client.Do(out)
+			require.NoError(t, err)
+			_ = resp.Body.Close()
+		})
+
+		const traceParent = "00-0102030405060708090a0b0c0d0e0f10-0102030405060708-01"
+		req := httptest.NewRequest(http.MethodGet, "/proxy", nil)
+		req.Header.Set("Traceparent", traceParent)
+		req.Header.Set("Baggage", "tenant=acme")
+		base.Server().Handler.ServeHTTP(httptest.NewRecorder(), req)
+
+		assert.Equal(t, traceParent, outbound.Get("Traceparent"))
+		assert.Equal(t, "tenant=acme", outbound.Get("Baggage"))
+	})
+}
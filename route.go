@@ -0,0 +1,269 @@
+// Copyright (c) 2026, Roel Schut. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package webapp
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/go-pogo/errors"
+	"github.com/go-pogo/serv"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// RouteMeta contains additional metadata of a route.
+type RouteMeta struct {
+	// Summary is a short description of the route.
+	Summary string
+	// Description is an extended explanation of the route's behavior.
+	Description string
+	// Tags are used to group routes, e.g. in generated documentation.
+	Tags []string
+	// Request is the type of the decoded request body.
+	Request reflect.Type
+	// Response is the type of the encoded response body.
+	Response reflect.Type
+	// ResponseStatus is the status code of a successful response.
+	ResponseStatus int
+	// Telemetry contains the route's telemetry settings.
+	Telemetry RouteTelemetry
+	// Timeout is the max duration of handling a request. When zero, the
+	// default timeout set with [WithRouteTimeout] is used. A negative value
+	// disables the timeout.
+	Timeout time.Duration
+	// Streaming indicates the route streams its response. Streaming routes
+	// are never timed out, as their responses cannot be buffered.
+	Streaming bool
+	// Deprecation marks the route as deprecated when not nil.
+	Deprecation *RouteDeprecation
+}
+
+// RouteTelemetry contains the telemetry settings of a route.
+type RouteTelemetry struct {
+	// DisableTracing disables tracing of requests to the route.
+	DisableTracing bool
+	// SpanName replaces the default "{method} {route}" name of the request's
+	// span.
+	SpanName string
+	// Attributes are added to the request's span and metrics.
+	Attributes []attribute.KeyValue
+}
+
+// TypedHandler is a [http.Handler] with typed request and response values,
+// such as a [JSONHandler].
+type TypedHandler interface {
+	http.Handler
+	Types() (req, resp reflect.Type)
+	Status() int
+}
+
+// WithRouteMeta returns a copy of route with meta attached to its handler.
+// Use [RouteMetaOf] to retrieve the attached [RouteMeta].
+func WithRouteMeta(route serv.Route, meta RouteMeta) serv.Route {
+	if mh, ok := route.Handler.(*metaHandler); ok {
+		route.Handler = mh.Handler
+	}
+	route.Handler = &metaHandler{
+		Handler: route.Handler,
+		meta:    meta,
+	}
+	return route
+}
+
+// RouteMetaOf returns the [RouteMeta] attached to the route's handler using
+// [WithRouteMeta]. When the route's handler is a [TypedHandler], its types
+// and status are added to the [RouteMeta] when not already set.
+func RouteMetaOf(route serv.Route) (RouteMeta, bool) {
+	var meta RouteMeta
+	var ok bool
+	handler := route.Handler
+	if mh, isMeta := handler.(*metaHandler); isMeta {
+		meta, handler, ok = mh.meta, mh.Handler, true
+	}
+	if th, isTyped := handler.(TypedHandler); isTyped {
+		req, resp := th.Types()
+		if meta.Request == nil {
+			meta.Request = req
+		}
+		if meta.Response == nil {
+			meta.Response = resp
+		}
+		if meta.ResponseStatus == 0 {
+			meta.ResponseStatus = th.Status()
+		}
+		ok = true
+	}
+	return meta, ok
+}
+
+type metaHandler struct {
+	http.Handler
+	meta RouteMeta
+}
+
+// wrapRouteHandler wraps the handler of route while keeping its attached
+// [RouteMeta].
+func wrapRouteHandler(route serv.Route, wrap func(next http.Handler) http.Handler) serv.Route {
+	meta, ok := RouteMetaOf(route)
+	route.Handler = wrap(route.Handler)
+	if ok {
+		route = WithRouteMeta(route, meta)
+	}
+	return route
+}
+
+// Routes returns all routes registered on the [Base]'s router, in order of
+// registration. Use [RouteMetaOf] to get a route's metadata.
+func (base *Base) Routes() []serv.Route { return base.router.Routes() }
+
+const (
+	ErrUnknownRoute errors.Msg = "unknown route"
+	ErrRouteParams  errors.Msg = "invalid route parameters"
+)
+
+// URLFor returns the path of the route registered with name, with its
+// wildcards filled with params. Params are either a single map[string]any or
+// map[string]string with a value for each wildcard, or a value for each
+// wildcard in order of appearance in the route's pattern. Values are
+// formatted using [fmt.Sprint] and escaped using [url.PathEscape]. The value
+// of a "{name...}" wildcard may contain slashes, which are kept as is. A host
+// in the pattern is omitted from the returned path.
+//
+//	// route "files" with pattern "/users/{id}/files/{path...}"
+//	base.URLFor("files", 42, "docs/a b.txt")
+//	// "/users/42/files/docs/a%20b.txt"
+func (base *Base) URLFor(name string, params ...any) (string, error) {
+	route, ok := base.router.Route(name)
+	if !ok {
+		return "", &RouteError{
+			Route: serv.Route{Name: name},
+			Err:   errors.New(ErrUnknownRoute),
+		}
+	}
+
+	path, err := buildPath(route.Pattern, params)
+	if err != nil {
+		return "", &RouteError{
+			Route:  route,
+			Err:    errors.New(ErrRouteParams),
+			Detail: err.Error(),
+		}
+	}
+	return path, nil
+}
+
+func buildPath(pattern string, params []any) (string, error) {
+	if i := strings.IndexByte(pattern, '/'); i > 0 {
+		pattern = pattern[i:]
+	}
+
+	var named map[string]string
+	if len(params) == 1 {
+		switch m := params[0].(type) {
+		case map[string]string:
+			named = m
+		case map[string]any:
+			named = make(map[string]string, len(m))
+			for k, v := range m {
+				named[k] = fmt.Sprint(v)
+			}
+		}
+	}
+
+	var n int
+	segments := strings.Split(pattern, "/")
+	for i, seg := range segments {
+		if len(seg) < 2 || seg[0] != '{' || seg[len(seg)-1] != '}' {
+			continue
+		}
+
+		wildcard := seg[1 : len(seg)-1]
+		if wildcard == "$" {
+			segments[i] = ""
+			continue
+		}
+
+		wildcard, multi := strings.CutSuffix(wildcard, "...")
+		var val string
+		if named != nil {
+			v, ok := named[wildcard]
+			if !ok {
+				return "", errors.Errorf("missing value for wildcard %q", wildcard)
+			}
+			val = v
+		} else {
+			if n >= len(params) {
+				return "", errors.Errorf("missing value for wildcard %q", wildcard)
+			}
+			val = fmt.Sprint(params[n])
+			n++
+		}
+
+		if multi {
+			parts := strings.Split(val, "/")
+			for j, part := range parts {
+				parts[j] = url.PathEscape(part)
+			}
+			segments[i] = strings.Join(parts, "/")
+		} else {
+			segments[i] = url.PathEscape(val)
+		}
+	}
+	if named == nil && n != len(params) {
+		return "", errors.Errorf("got %d values for %d wildcards", len(params), n)
+	}
+	return strings.Join(segments, "/"), nil
+}
+
+// RouteInfo describes a registered route.
+type RouteInfo struct {
+	Name        string   `json:"name"`
+	Method      string   `json:"method,omitempty"`
+	Pattern     string   `json:"pattern"`
+	Summary     string   `json:"summary,omitempty"`
+	Description string   `json:"description,omitempty"`
+	Tags        []string `json:"tags,omitempty"`
+}
+
+// RouteInfoOf returns the [RouteInfo] of route.
+func RouteInfoOf(route serv.Route) RouteInfo {
+	meta, _ := RouteMetaOf(route)
+	return RouteInfo{
+		Name:        route.Name,
+		Method:      route.Method,
+		Pattern:     route.Pattern,
+		Summary:     meta.Summary,
+		Description: meta.Description,
+		Tags:        meta.Tags,
+	}
+}
+
+// RoutesHTTPHandler returns a [http.Handler] which responds with a JSON list of
+// the [RouteInfo] of all routes returned by routes.
+func RoutesHTTPHandler(routes func() []serv.Route) http.Handler {
+	return http.HandlerFunc(func(wri http.ResponseWriter, _ *http.Request) {
+		list := routes()
+		infos := make([]RouteInfo, 0, len(list))
+		for _, route := range list {
+			infos = append(infos, RouteInfoOf(route))
+		}
+
+		wri.Header().Set("Content-Type", contentTypeJSON)
+		_ = json.NewEncoder(wri).Encode(infos)
+	})
+}
+
+// splitPattern splits the method from a [http.ServeMux] pattern.
+func splitPattern(pattern string) (method, rest string) {
+	if i := strings.IndexAny(pattern, " \t"); i >= 0 {
+		return pattern[:i], strings.TrimLeft(pattern[i+1:], " \t")
+	}
+	return "", pattern
+}
@@ -0,0 +1,135 @@
+// Copyright (c) 2026, Roel Schut. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package webapp
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-pogo/serv"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBase_Routes(t *testing.T) {
+	base, err := New(
+		WithHealthChecker(),
+		WithRoutesRoute(""),
+		WithGroup("/api", serv.RoutesRegistererFunc(func(rh serv.RouteHandler) {
+			rh.HandleRoute(WithRouteMeta(serv.Route{
+				Name:    "items",
+				Method:  http.MethodGet,
+				Pattern: "/items",
+				Handler: http.NotFoundHandler(),
+			}, RouteMeta{
+				Summary: "List items",
+				Tags:    []string{"items"},
+			}))
+		}), WithGroupName("api")),
+	)
+	require.NoError(t, err)
+
+	routes := base.Routes()
+	require.Len(t, routes, 3)
+
+	meta, ok := RouteMetaOf(routes[2])
+	assert.True(t, ok)
+	assert.Equal(t, "List items", meta.Summary)
+
+	rec := httptest.NewRecorder()
+	base.Server().Handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, RoutesPathPattern, nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	var have []RouteInfo
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &have))
+	assert.Equal(t, []string{HealthCheckRoute, RoutesRoute, "api.items"}, []string{
+		have[0].Name, have[1].Name, have[2].Name,
+	})
+	assert.Equal(t, RouteInfo{
+		Name:    "api.items",
+		Method:  http.MethodGet,
+		Pattern: "/api/items",
+		Summary: "List items",
+		Tags:    []string{"items"},
+	}, have[2])
+}
+
+func TestBase_URLFor(t *testing.T) {
+	base, err := New(
+		WithHealthChecker(),
+		WithGroup("/api", serv.RoutesRegistererFunc(func(rh serv.RouteHandler) {
+			rh.HandleRoute(serv.Route{
+				Name:    "file",
+				Method:  http.MethodGet,
+				Pattern: "/users/{id}/files/{path...}",
+				Handler: http.NotFoundHandler(),
+			})
+			rh.HandleRoute(serv.Route{
+				Name:    "index",
+				Pattern: "example.com/{$}",
+				Handler: http.NotFoundHandler(),
+			})
+		}), WithGroupName("api")),
+	)
+	require.NoError(t, err)
+
+	tests := map[string]struct {
+		name    string
+		params  []any
+		want    string
+		wantErr error
+	}{
+		"static": {
+			name: HealthCheckRoute,
+			want: "/healthy",
+		},
+		"positional": {
+			name:   "api.file",
+			params: []any{42, "docs/a b?.txt"},
+			want:   "/api/users/42/files/docs/a%20b%3F.txt",
+		},
+		"map": {
+			name:   "api.file",
+			params: []any{map[string]any{"id": "a/b", "path": "x"}},
+			want:   "/api/users/a%2Fb/files/x",
+		},
+		"host and end": {
+			name: "api.index",
+			want: "/api/",
+		},
+		"unknown": {
+			name:    "foo",
+			wantErr: ErrUnknownRoute,
+		},
+		"missing param": {
+			name:    "api.file",
+			params:  []any{42},
+			wantErr: ErrRouteParams,
+		},
+		"too many params": {
+			name:    "api.file",
+			params:  []any{42, "x", "y"},
+			wantErr: ErrRouteParams,
+		},
+		"missing map key": {
+			name:    "api.file",
+			params:  []any{map[string]string{"id": "1"}},
+			wantErr: ErrRouteParams,
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			have, err := base.URLFor(tc.name, tc.params...)
+			if tc.wantErr != nil {
+				assert.ErrorIs(t, err, tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, have)
+		})
+	}
+}
@@ -5,54 +5,525 @@
 package webapp
 
 import (
+	"context"
+	"fmt"
+	"net"
 	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
 
+	"github.com/go-pogo/errors"
 	"github.com/go-pogo/serv"
 	"github.com/go-pogo/webapp/logger"
+	"github.com/go-pogo/webapp/problem"
 	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/metric/noop"
 	semconv "go.opentelemetry.io/otel/semconv/v1.40.0"
 	"go.opentelemetry.io/otel/trace"
 )
 
-var _ serv.Router = (*router)(nil)
+const (
+	ErrDuplicateRouteName errors.Msg = "duplicate route name"
+	ErrConflictingRoute   errors.Msg = "conflicting route pattern"
+	ErrInvalidRoute       errors.Msg = "invalid route pattern"
+)
+
+// RouteError is the error that occurs when a [serv.Route] cannot be
+// registered. Err is one of [ErrDuplicateRouteName], [ErrConflictingRoute] or
+// [ErrInvalidRoute].
+type RouteError struct {
+	Route  serv.Route
+	Err    error
+	Detail string
+}
+
+func (e *RouteError) Error() string {
+	var sb strings.Builder
+	sb.WriteString(e.Err.Error())
+	sb.WriteString(": route ")
+	if e.Route.Name != "" {
+		sb.WriteString(strconv.Quote(e.Route.Name))
+		if e.Route.Pattern != "" {
+			sb.WriteString(" with pattern ")
+		}
+	}
+	if e.Route.Pattern != "" {
+		sb.WriteString(strconv.Quote(routePattern(e.Route)))
+	}
+	if e.Detail != "" {
+		sb.WriteString(": ")
+		sb.WriteString(e.Detail)
+	}
+	return sb.String()
+}
+
+func (e *RouteError) Unwrap() error { return e.Err }
+
+// meterName is the name of the [metric.Meter] used to create the router's
+// metrics.
+const meterName = "github.com/go-pogo/webapp"
+
+const (
+	// OptionsHandlerName is the handler name of automatically answered OPTIONS
+	// requests, see [serv.HandlerName].
+	OptionsHandlerName = "options"
+	// MethodNotAllowedHandlerName is the handler name of requests which are
+	// answered with a 405 response, see [serv.HandlerName].
+	MethodNotAllowedHandlerName = "method_not_allowed"
+)
+
+// RouteHostKey is the attribute key of the host of a host-scoped route.
+const RouteHostKey = attribute.Key("webapp.route.host")
+
+var (
+	_ serv.Router = (*router)(nil)
+
+	noopMeterProvider metric.MeterProvider = noop.NewMeterProvider()
+)
 
+// router is similar to [serv.ServeMux]. The main difference is requests are
+// served using [http.ServeMux.ServeHTTP], so wildcards in patterns are
+// available via [http.Request.PathValue].
 type router struct {
-	*serv.ServeMux
+	*http.ServeMux
+
+	mut      sync.RWMutex
+	notFound http.Handler
+	notAllow http.Handler
+	hosts    map[string]http.Handler
+	methods  []string
+	routes   []serv.Route
+	names    map[string]int
+	patterns map[string]int
+	err      error
+
+	log     logger.RegisterRouteLogger
+	trace   bool
+	timeout atomic.Int64
+	// sealed is set once New returns, after which registration errors can
+	// no longer be returned and HandleRoute panics instead
+	sealed atomic.Bool
+
+	meterProvider metric.MeterProvider
+	depLog        logger.DeprecatedRouteLogger
+	panicLog      problem.PanicLogger
+	depCalls      metric.Int64Counter
+	depCallsOnce  sync.Once
+}
+
+func newRouter() *router {
+	return &router{
+		ServeMux: http.NewServeMux(),
+		names:    make(map[string]int),
+		patterns: make(map[string]int),
+	}
+}
+
+// Err returns all errors that occurred while registering routes.
+func (mux *router) Err() error {
+	mux.mut.RLock()
+	defer mux.mut.RUnlock()
+	return mux.err
+}
+
+func (mux *router) NotFoundHandler() http.Handler {
+	mux.mut.RLock()
+	defer mux.mut.RUnlock()
+	return mux.notFound
+}
+
+func (mux *router) WithNotFoundHandler(h http.Handler) {
+	mux.mut.Lock()
+	mux.notFound = h
+	mux.mut.Unlock()
+}
+
+func (mux *router) methodNotAllowedHandler() http.Handler {
+	mux.mut.RLock()
+	defer mux.mut.RUnlock()
+	return mux.notAllow
+}
+
+func (mux *router) withMethodNotAllowedHandler(h http.Handler) {
+	mux.mut.Lock()
+	mux.notAllow = h
+	mux.mut.Unlock()
+}
+
+// withHostNotFoundHandler sets the handler which is used for requests to host
+// that do not match any route.
+func (mux *router) withHostNotFoundHandler(host string, h http.Handler) {
+	mux.mut.Lock()
+	defer mux.mut.Unlock()
+	if mux.hosts == nil {
+		mux.hosts = make(map[string]http.Handler, 1)
+	}
+	mux.hosts[strings.ToLower(host)] = h
+}
+
+// notFoundHandler returns the not found handler of host, or the default not
+// found handler when host does not have one.
+func (mux *router) notFoundHandler(host string) http.Handler {
+	mux.mut.RLock()
+	defer mux.mut.RUnlock()
+	if h, ok := mux.hosts[strings.ToLower(stripPort(host))]; ok {
+		return h
+	}
+	return mux.notFound
+}
+
+func (mux *router) Routes() []serv.Route {
+	mux.mut.RLock()
+	defer mux.mut.RUnlock()
+	return append(make([]serv.Route, 0, len(mux.routes)), mux.routes...)
+}
+
+// Route returns the registered route with name.
+func (mux *router) Route(name string) (serv.Route, bool) {
+	mux.mut.RLock()
+	defer mux.mut.RUnlock()
+	if i, ok := mux.names[name]; ok {
+		return mux.routes[i], true
+	}
+	return serv.Route{}, false
+}
+
+type ctxRouteMatchKey struct{}
+
+type routeMatch struct {
+	pattern string
+	route   serv.Route
+	found   bool
+}
+
+// resolveRoute matches req once against the registered routes and adds the
+// result to its context, so the handlers wrapping the router, and the router
+// itself, do not need to match req again.
+func (mux *router) resolveRoute(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(wri http.ResponseWriter, req *http.Request) {
+		m := mux.resolve(req)
+		next.ServeHTTP(wri, req.WithContext(context.WithValue(req.Context(), ctxRouteMatchKey{}, m)))
+	})
+}
+
+func (mux *router) resolve(req *http.Request) *routeMatch {
+	_, pattern := mux.ServeMux.Handler(req)
+	m := &routeMatch{pattern: pattern}
+	if pattern == "" {
+		return m
+	}
+
+	mux.mut.RLock()
+	defer mux.mut.RUnlock()
+	if i, ok := mux.patterns[pattern]; ok {
+		m.route, m.found = mux.routes[i], true
+	}
+	return m
+}
+
+// routeMatchOf returns the match of req added by resolveRoute, or matches req
+// when it is not resolved yet.
+func (mux *router) routeMatchOf(req *http.Request) *routeMatch {
+	if m, ok := req.Context().Value(ctxRouteMatchKey{}).(*routeMatch); ok {
+		return m
+	}
+	return mux.resolve(req)
+}
+
+// routeFromContext returns the route resolved by resolveRoute.
+func routeFromContext(ctx context.Context) (serv.Route, bool) {
+	if m, ok := ctx.Value(ctxRouteMatchKey{}).(*routeMatch); ok {
+		return m.route, m.found
+	}
+	return serv.Route{}, false
+}
+
+// match returns the registered route which matches req.
+func (mux *router) match(req *http.Request) (serv.Route, bool) {
+	m := mux.routeMatchOf(req)
+	return m.route, m.found
+}
 
-	log   logger.RegisterRouteLogger
-	trace bool
+// traceFilter returns false when req matches a route with disabled tracing.
+// It is used as [otelhttp.Filter].
+func (mux *router) traceFilter(req *http.Request) bool {
+	route, ok := mux.match(req)
+	if !ok {
+		return true
+	}
+	meta, _ := RouteMetaOf(route)
+	return !meta.Telemetry.DisableTracing
 }
 
 func (mux *router) Handle(pattern string, handler http.Handler) {
+	method, pattern := splitPattern(pattern)
 	mux.HandleRoute(serv.Route{
+		Method:  method,
 		Pattern: pattern,
 		Handler: handler,
 	})
 }
 
 func (mux *router) HandleFunc(pattern string, handler func(http.ResponseWriter, *http.Request)) {
-	mux.HandleRoute(serv.Route{
-		Pattern: pattern,
-		Handler: http.HandlerFunc(handler),
-	})
+	mux.Handle(pattern, http.HandlerFunc(handler))
 }
 
+// HandleRoute registers route. Instead of panicking, as [http.ServeMux] does,
+// a [RouteError] is collected when route's name is already registered or its
+// pattern is invalid or conflicts with an already registered pattern. The
+// collected errors are available via Err. Once [New] has returned, HandleRoute
+// panics with the [RouteError] instead, like [http.ServeMux] does.
 func (mux *router) HandleRoute(route serv.Route) {
+	if err := mux.register(route); err != nil {
+		if mux.sealed.Load() {
+			panic(err)
+		}
+
+		mux.mut.Lock()
+		mux.err = errors.Append(mux.err, err)
+		mux.mut.Unlock()
+		return
+	}
 	if mux.log != nil {
 		mux.log.LogRegisterRoute(route)
 	}
-	if mux.trace {
-		attr := semconv.HTTPRoute(route.Name)
-		handler := route.Handler
+}
+
+// seal makes HandleRoute panic on registration errors, see sealed.
+func (mux *router) seal() { mux.sealed.Store(true) }
+
+func (mux *router) register(route serv.Route) error {
+	mux.mut.Lock()
+	defer mux.mut.Unlock()
+
+	if route.Name != "" {
+		if _, exists := mux.names[route.Name]; exists {
+			return &RouteError{
+				Route: route,
+				Err:   errors.New(ErrDuplicateRouteName),
+			}
+		}
+	}
+
+	meta, _ := RouteMetaOf(route)
+	handler := mux.timeoutRoute(meta, route.GetHandler())
+	if meta.Deprecation != nil {
+		handler = mux.deprecateRoute(route, *meta.Deprecation, handler)
+	}
+	handler = mux.traceRoute(route, meta, handler)
+	if err := mux.handle(route, handler); err != nil {
+		return err
+	}
+
+	if route.Name != "" {
+		mux.names[route.Name] = len(mux.routes)
+	}
+	if route.Method != "" && !slices.Contains(mux.methods, route.Method) {
+		mux.methods = append(mux.methods, route.Method)
+	}
+	mux.patterns[routePattern(route)] = len(mux.routes)
+	mux.routes = append(mux.routes, route)
+	return nil
+}
+
+// handle registers handler with the pattern of route on the underlying
+// [http.ServeMux] and returns a [RouteError] when it panics.
+func (mux *router) handle(route serv.Route, handler http.Handler) (err error) {
+	pattern := routePattern(route)
+	defer func() {
+		if r := recover(); r != nil {
+			kind := ErrConflictingRoute
+			if !validPattern(pattern) {
+				kind = ErrInvalidRoute
+			}
+			err = &RouteError{
+				Route:  route,
+				Err:    errors.New(kind),
+				Detail: strings.TrimPrefix(fmt.Sprint(r), "http: "),
+			}
+		}
+	}()
+
+	mux.ServeMux.Handle(pattern, handler)
+	return nil
+}
+
+// validPattern reports whether pattern can be registered on an empty
+// [http.ServeMux]. A pattern which is valid, but cannot be registered on the
+// router's [http.ServeMux], conflicts with an already registered pattern.
+func validPattern(pattern string) (valid bool) {
+	defer func() {
+		if recover() != nil {
+			valid = false
+		}
+	}()
+
+	http.NewServeMux().Handle(pattern, http.NotFoundHandler())
+	return true
+}
+
+// ServeHTTP is part of the [http.Handler] interface. Requests which match a
+// route's path, but not its method, are answered automatically. OPTIONS
+// requests receive a 204 response and other requests a 405 response, both
+// with an "Allow" header listing the allowed methods.
+func (mux *router) ServeHTTP(wri http.ResponseWriter, req *http.Request) {
+	if req.RequestURI == "*" {
+		mux.ServeMux.ServeHTTP(wri, req)
+		return
+	}
+
+	pattern := mux.routeMatchOf(req).pattern
+	if rd := logger.RequestDetailsFromContext(req.Context()); rd != nil {
+		// the matched pattern contains the host of host-scoped routes
+		rd.Route = pattern
+	}
+
+	if pattern == "" {
+		if allow := mux.allowedMethods(req); len(allow) != 0 {
+			wri.Header().Set("Allow", strings.Join(allow, ", "))
+			if req.Method == http.MethodOptions {
+				serv.AddHandlerName(OptionsHandlerName, http.HandlerFunc(noContent)).ServeHTTP(wri, req)
+				return
+			}
 
-		route.Handler = http.HandlerFunc(func(wri http.ResponseWriter, req *http.Request) {
-			span := trace.SpanFromContext(req.Context())
-			span.SetAttributes(attr)
+			handler := mux.methodNotAllowedHandler()
+			if handler == nil {
+				handler = http.HandlerFunc(methodNotAllowed)
+			}
+			serv.AddHandlerName(MethodNotAllowedHandlerName, handler).ServeHTTP(wri, req)
+			return
+		}
+		if notFound := mux.notFoundHandler(req.Host); notFound != nil {
+			notFound.ServeHTTP(wri, req)
+			return
+		}
+	}
+	mux.ServeMux.ServeHTTP(wri, req)
+}
+
+// standardMethods are checked by allowedMethods, in addition to the custom
+// methods of registered routes.
+var standardMethods = []string{
+	http.MethodGet,
+	http.MethodHead,
+	http.MethodPost,
+	http.MethodPut,
+	http.MethodPatch,
+	http.MethodDelete,
+}
 
-			labeler, _ := otelhttp.LabelerFromContext(req.Context())
-			labeler.Add(attr)
+// allowedMethods returns the methods of the routes which match the path of
+// req.
+func (mux *router) allowedMethods(req *http.Request) []string {
+	mux.mut.RLock()
+	methods := mux.methods
+	mux.mut.RUnlock()
+
+	var allow []string
+	r := *req
+	check := func(method string) {
+		if slices.Contains(allow, method) {
+			return
+		}
+		r.Method = method
+		if _, pattern := mux.ServeMux.Handler(&r); pattern != "" {
+			allow = append(allow, method)
+		}
+	}
+	for _, method := range standardMethods {
+		check(method)
+	}
+	for _, method := range methods {
+		check(method)
+	}
+	if len(allow) != 0 {
+		check(http.MethodOptions)
+		if !slices.Contains(allow, http.MethodOptions) {
+			allow = append(allow, http.MethodOptions)
+		}
+	}
+	return allow
+}
+
+func noContent(wri http.ResponseWriter, _ *http.Request) {
+	wri.WriteHeader(http.StatusNoContent)
+}
+
+func methodNotAllowed(wri http.ResponseWriter, _ *http.Request) {
+	http.Error(wri, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+}
+
+// traceRoute wraps handler so the route's pattern and [RouteTelemetry]
+// attributes are added to the request's span and metrics. Whether tracing is
+// enabled is checked when a request is served, so routes registered before
+// telemetry is configured are traced as well.
+func (mux *router) traceRoute(route serv.Route, meta RouteMeta, handler http.Handler) http.Handler {
+	if meta.Telemetry.DisableTracing {
+		return handler
+	}
+
+	host, path := splitHost(route.Pattern)
+
+	attrs := make([]attribute.KeyValue, 0, len(meta.Telemetry.Attributes)+2)
+	attrs = append(attrs, semconv.HTTPRoute(path))
+	if host != "" {
+		attrs = append(attrs, RouteHostKey.String(host))
+	}
+	attrs = append(attrs, meta.Telemetry.Attributes...)
+
+	spanName := meta.Telemetry.SpanName
+	if spanName == "" && route.Method != "" {
+		spanName = route.Method + " " + path
+	}
+
+	return http.HandlerFunc(func(wri http.ResponseWriter, req *http.Request) {
+		if !mux.trace {
 			handler.ServeHTTP(wri, req)
-		})
+			return
+		}
+
+		span := trace.SpanFromContext(req.Context())
+		span.SetAttributes(attrs...)
+		if spanName != "" {
+			span.SetName(spanName)
+		}
+
+		labeler, _ := otelhttp.LabelerFromContext(req.Context())
+		labeler.Add(attrs...)
+		handler.ServeHTTP(wri, req)
+	})
+}
+
+// routePattern returns the full [http.ServeMux] pattern of route.
+func routePattern(route serv.Route) string {
+	if route.Method == "" {
+		return route.Pattern
+	}
+	return route.Method + " " + route.Pattern
+}
+
+// routePath returns the path of pattern, without its host.
+func routePath(pattern string) string {
+	_, path := splitHost(pattern)
+	return path
+}
+
+// splitHost splits the host from the path of pattern.
+func splitHost(pattern string) (host, path string) {
+	if i := strings.IndexByte(pattern, '/'); i > 0 {
+		return pattern[:i], pattern[i:]
+	}
+	return "", pattern
+}
+
+func stripPort(host string) string {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		return h
 	}
-	mux.ServeMux.HandleRoute(route)
+	return host
 }
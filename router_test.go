@@ -0,0 +1,240 @@
+// Copyright (c) 2026, Roel Schut. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package webapp
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-pogo/errors"
+	"github.com/go-pogo/serv"
+	"github.com/go-pogo/telemetry"
+	"github.com/go-pogo/webapp/problem"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.40.0"
+)
+
+func TestRouter_HandleRoute(t *testing.T) {
+	tests := map[string]struct {
+		routes  []serv.Route
+		wantErr error
+	}{
+		"duplicate name": {
+			routes: []serv.Route{
+				{Name: "items", Method: http.MethodGet, Pattern: "/items"},
+				{Name: "items", Method: http.MethodPost, Pattern: "/items"},
+			},
+			wantErr: ErrDuplicateRouteName,
+		},
+		"conflicting pattern": {
+			routes: []serv.Route{
+				{Name: "a", Method: http.MethodGet, Pattern: "/items/{id}"},
+				{Name: "b", Method: http.MethodGet, Pattern: "/items/{name}"},
+			},
+			wantErr: ErrConflictingRoute,
+		},
+		"invalid pattern": {
+			routes: []serv.Route{
+				{Name: "a", Method: http.MethodGet, Pattern: "/items/{id"},
+			},
+			wantErr: ErrInvalidRoute,
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			base, err := New(WithRoutesRegisterer(serv.RoutesRegistererFunc(func(rh serv.RouteHandler) {
+				for _, route := range tc.routes {
+					route.Handler = http.NotFoundHandler()
+					rh.HandleRoute(route)
+				}
+			})))
+			assert.Nil(t, base)
+			require.Error(t, err)
+			assert.ErrorIs(t, err, ErrApplyOptions)
+			assert.ErrorIs(t, err, tc.wantErr)
+
+			var routeErr *RouteError
+			require.ErrorAs(t, err, &routeErr)
+			assert.Equal(t, tc.routes[len(tc.routes)-1].Name, routeErr.Route.Name)
+		})
+	}
+
+	t.Run("valid", func(t *testing.T) {
+		mux := newRouter()
+		mux.HandleFunc("GET /items/{id}", func(http.ResponseWriter, *http.Request) {})
+		mux.HandleFunc("POST /items/{id}", func(http.ResponseWriter, *http.Request) {})
+		mux.HandleFunc("/other", func(http.ResponseWriter, *http.Request) {})
+		assert.NoError(t, mux.Err())
+		assert.Len(t, mux.Routes(), 3)
+	})
+
+	t.Run("after New", func(t *testing.T) {
+		base, err := New(WithRoutesRegisterer(serv.RoutesRegistererFunc(func(rh serv.RouteHandler) {
+			rh.HandleRoute(serv.Route{Name: "items", Pattern: "/items", Handler: http.NotFoundHandler()})
+		})))
+		require.NoError(t, err)
+
+		assert.PanicsWithError(t, `duplicate route name: route "items" with pattern "/api/other"`, func() {
+			base.Group("/api").HandleRoute(serv.Route{Name: "items", Pattern: "/other", Handler: http.NotFoundHandler()})
+		})
+		assert.Panics(t, func() {
+			base.RouteHandler().HandleRoute(serv.Route{Pattern: "/items", Handler: http.NotFoundHandler()})
+		})
+		assert.NoError(t, base.router.Err())
+	})
+}
+
+func TestRouteError_Error(t *testing.T) {
+	err := &RouteError{
+		Route:  serv.Route{Name: "items", Method: http.MethodGet, Pattern: "/items"},
+		Err:    errors.New(ErrDuplicateRouteName),
+		Detail: "details",
+	}
+	assert.Equal(t, `duplicate route name: route "items" with pattern "GET /items": details`, err.Error())
+}
+
+// withTestTracing is an [Option] which traces requests using a
+// [tracetest.InMemoryExporter].
+func withTestTracing(exp *tracetest.InMemoryExporter) Option {
+	return WithTelemetry(telemetry.New(nil, sdktrace.NewTracerProvider(
+		sdktrace.WithSyncer(exp),
+	)))
+}
+
+func TestRouter_trace(t *testing.T) {
+	exp := tracetest.NewInMemoryExporter()
+	base, err := New(
+		withTestTracing(exp),
+		WithHealthChecker(),
+		WithIgnoreFaviconRoute(),
+		WithRoutesRegisterer(serv.RoutesRegistererFunc(func(rh serv.RouteHandler) {
+			rh.HandleRoute(serv.Route{
+				Name:    "item",
+				Method:  http.MethodGet,
+				Pattern: "/items/{id}",
+				Handler: http.NotFoundHandler(),
+			})
+			rh.HandleRoute(WithRouteMeta(serv.Route{
+				Name:    "custom",
+				Method:  http.MethodGet,
+				Pattern: "/custom",
+				Handler: http.NotFoundHandler(),
+			}, RouteMeta{Telemetry: RouteTelemetry{
+				SpanName:   "custom span",
+				Attributes: []attribute.KeyValue{attribute.String("foo", "bar")},
+			}}))
+		})),
+	)
+	require.NoError(t, err)
+	// ignore the spans of New
+	exp.Reset()
+
+	for _, target := range []string{"/items/1", "/custom", "/healthy", "/favicon.ico"} {
+		base.Server().Handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, target, nil))
+	}
+
+	spans := exp.GetSpans()
+	require.Len(t, spans, 2)
+
+	assert.Equal(t, "GET /items/{id}", spans[0].Name)
+	assert.Contains(t, spans[0].Attributes, semconv.HTTPRoute("/items/{id}"))
+
+	assert.Equal(t, "custom span", spans[1].Name)
+	assert.Contains(t, spans[1].Attributes, semconv.HTTPRoute("/custom"))
+	assert.Contains(t, spans[1].Attributes, attribute.String("foo", "bar"))
+}
+
+func TestRouter_traceRoute(t *testing.T) {
+	exp := tracetest.NewInMemoryExporter()
+	base, err := New(
+		// registered before telemetry is enabled
+		WithRoutesRegisterer(serv.RoutesRegistererFunc(func(rh serv.RouteHandler) {
+			rh.HandleRoute(serv.Route{
+				Name:    "item",
+				Method:  http.MethodGet,
+				Pattern: "/items/{id}",
+				Handler: http.NotFoundHandler(),
+			})
+		})),
+		withTestTracing(exp),
+	)
+	require.NoError(t, err)
+	exp.Reset()
+
+	base.Server().Handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/items/1", nil))
+	spans := exp.GetSpans()
+	require.Len(t, spans, 1)
+	assert.Equal(t, "GET /items/{id}", spans[0].Name)
+	assert.Contains(t, spans[0].Attributes, semconv.HTTPRoute("/items/{id}"))
+}
+
+func TestRouter_ServeHTTP(t *testing.T) {
+	base, err := New(WithProblemDetails())
+	require.NoError(t, err)
+
+	mux := base.router
+	mux.HandleFunc("GET /items", func(wri http.ResponseWriter, _ *http.Request) {
+		wri.Header().Set("Content-Type", "text/plain")
+		_, _ = wri.Write([]byte("items"))
+	})
+	mux.HandleFunc("POST /items", func(wri http.ResponseWriter, _ *http.Request) {
+		wri.WriteHeader(http.StatusCreated)
+	})
+	mux.HandleFunc("PURGE /items", func(http.ResponseWriter, *http.Request) {})
+	require.NoError(t, mux.Err())
+
+	serve := func(method, target string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		base.Server().Handler.ServeHTTP(rec, httptest.NewRequest(method, target, nil))
+		return rec
+	}
+
+	const allow = "GET, HEAD, POST, PURGE, OPTIONS"
+
+	t.Run("get", func(t *testing.T) {
+		rec := serve(http.MethodGet, "/items")
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "items", rec.Body.String())
+	})
+	t.Run("head", func(t *testing.T) {
+		// the body is discarded by the http.Server
+		srv := httptest.NewServer(base.Server().Handler)
+		defer srv.Close()
+
+		req, err := http.NewRequest(http.MethodHead, srv.URL+"/items", nil)
+		require.NoError(t, err)
+		resp, err := srv.Client().Do(req)
+		require.NoError(t, err)
+		defer func() { _ = resp.Body.Close() }()
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "text/plain", resp.Header.Get("Content-Type"))
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		assert.Empty(t, body)
+	})
+	t.Run("options", func(t *testing.T) {
+		rec := serve(http.MethodOptions, "/items")
+		assert.Equal(t, http.StatusNoContent, rec.Code)
+		assert.Equal(t, allow, rec.Header().Get("Allow"))
+	})
+	t.Run("method not allowed", func(t *testing.T) {
+		rec := serve(http.MethodDelete, "/items")
+		assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+		assert.Equal(t, allow, rec.Header().Get("Allow"))
+		assert.Equal(t, problem.ContentType, rec.Header().Get("Content-Type"))
+	})
+	t.Run("not found", func(t *testing.T) {
+		rec := serve(http.MethodOptions, "/other")
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+		assert.Empty(t, rec.Header().Get("Allow"))
+	})
+}
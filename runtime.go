@@ -0,0 +1,185 @@
+// Copyright (c) 2026, Roel Schut. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package webapp
+
+import (
+	"context"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/go-pogo/errors"
+	runtimemetrics "go.opentelemetry.io/contrib/instrumentation/runtime"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/semconv/v1.40.0/processconv"
+)
+
+const ErrReadProcStat errors.Msg = "failed to read process stat"
+
+// procStatPath is the file from which process metrics are read.
+var procStatPath = "/proc/self/stat"
+
+// clockTicks is the number of clock ticks per second (USER_HZ) used by the
+// kernel to report cpu times in /proc. It is assumed to be 100, which is the
+// fixed value of USER_HZ on all Linux architectures supported by Go, as the
+// actual value can only be read using sysconf(_SC_CLK_TCK).
+const clockTicks = 100
+
+// WithRuntimeMetrics collects Go runtime metrics, like GC, goroutine and heap
+// statistics, and process metrics, like cpu time, memory usage, thread and
+// file descriptor counts, using the MeterProvider of the
+// [telemetry.Telemetry] configured with [WithTelemetryConfig]. Process metrics
+// are read from /proc and are omitted on systems without it. Collection stops
+// when [Base.Shutdown] is called.
+//
+// This option replaces the runtime metrics collector which is started by
+// default when building the telemetry, see
+// [telemetry.MeterProviderBuilder].DisableRuntimeMetrics, so the Go runtime
+// metrics are not collected twice.
+func WithRuntimeMetrics() Option {
+	return func(_ *Base, config *config) error {
+		config.runtimeMetrics = true
+		return nil
+	}
+}
+
+// setupRuntimeMetrics starts the collection of runtime and process metrics. It
+// is called after the telemetry is built.
+func (base *Base) setupRuntimeMetrics(conf *config) error {
+	if !conf.runtimeMetrics || base.telem == nil {
+		return nil
+	}
+
+	mp := base.telem.MeterProvider()
+	if err := runtimemetrics.Start(runtimemetrics.WithMeterProvider(mp)); err != nil {
+		return errors.WithStack(err)
+	}
+
+	if _, err := os.Stat(procStatPath); err != nil {
+		// process metrics are not available on this system
+		return nil
+	}
+
+	reg, err := registerProcessMetrics(mp.Meter(meterName))
+	if err != nil {
+		return err
+	}
+
+	base.callbacks = append(base.callbacks, reg)
+	return nil
+}
+
+func registerProcessMetrics(meter metric.Meter) (metric.Registration, error) {
+	cpuTime, err := processconv.NewCPUTime(meter)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	memUsage, err := observableUpDownCounter(meter, processconv.MemoryUsage{})
+	if err != nil {
+		return nil, err
+	}
+	memVirtual, err := observableUpDownCounter(meter, processconv.MemoryVirtual{})
+	if err != nil {
+		return nil, err
+	}
+	threads, err := observableUpDownCounter(meter, processconv.ThreadCount{})
+	if err != nil {
+		return nil, err
+	}
+	fds, err := observableUpDownCounter(meter, processconv.UnixFileDescriptorCount{})
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		userMode   = metric.WithAttributes(cpuTime.AttrCPUMode(processconv.CPUModeUser))
+		systemMode = metric.WithAttributes(cpuTime.AttrCPUMode(processconv.CPUModeSystem))
+	)
+
+	reg, err := meter.RegisterCallback(func(_ context.Context, obs metric.Observer) error {
+		stat, err := readProcStat(procStatPath)
+		if err != nil {
+			return err
+		}
+
+		obs.ObserveFloat64(cpuTime.Inst(), stat.userTime, userMode)
+		obs.ObserveFloat64(cpuTime.Inst(), stat.systemTime, systemMode)
+		obs.ObserveInt64(memUsage, stat.rss)
+		obs.ObserveInt64(memVirtual, stat.vsize)
+		obs.ObserveInt64(threads, stat.threads)
+
+		if entries, err := os.ReadDir("/proc/self/fd"); err == nil {
+			obs.ObserveInt64(fds, int64(len(entries)))
+		}
+		return nil
+	},
+		cpuTime.Inst(),
+		memUsage,
+		memVirtual,
+		threads,
+		fds,
+	)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return reg, nil
+}
+
+type semconvInstrument interface {
+	Name() string
+	Unit() string
+	Description() string
+}
+
+// observableUpDownCounter creates an asynchronous version of the semantic
+// convention's synchronous up-down counter instrument.
+func observableUpDownCounter(meter metric.Meter, inst semconvInstrument) (metric.Int64ObservableUpDownCounter, error) {
+	c, err := meter.Int64ObservableUpDownCounter(inst.Name(),
+		metric.WithUnit(inst.Unit()),
+		metric.WithDescription(inst.Description()),
+	)
+	return c, errors.WithStack(err)
+}
+
+type procStat struct {
+	userTime   float64 // in seconds
+	systemTime float64 // in seconds
+	threads    int64
+	vsize      int64 // in bytes
+	rss        int64 // in bytes
+}
+
+// readProcStat reads and parses the stat file of a process, see proc(5).
+func readProcStat(path string) (procStat, error) {
+	var stat procStat
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return stat, errors.Wrap(err, ErrReadProcStat)
+	}
+
+	// the process' name may contain spaces and parentheses, the remaining
+	// fields start after its closing parenthesis with field 3 (state)
+	i := strings.LastIndexByte(string(data), ')')
+	if i < 0 {
+		return stat, errors.New(ErrReadProcStat)
+	}
+
+	fields := strings.Fields(string(data[i+1:]))
+	if len(fields) < 22 {
+		return stat, errors.New(ErrReadProcStat)
+	}
+
+	field := func(n int) int64 {
+		v, _ := strconv.ParseInt(fields[n-3], 10, 64)
+		return v
+	}
+
+	stat.userTime = float64(field(14)) / clockTicks
+	stat.systemTime = float64(field(15)) / clockTicks
+	stat.threads = field(20)
+	stat.vsize = field(23)
+	stat.rss = field(24) * int64(os.Getpagesize())
+	return stat, nil
+}
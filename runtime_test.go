@@ -0,0 +1,84 @@
+// Copyright (c) 2026, Roel Schut. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package webapp
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-pogo/telemetry"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithRuntimeMetrics(t *testing.T) {
+	if _, err := os.Stat(procStatPath); err != nil {
+		t.Skip("procfs is not available")
+	}
+
+	base, err := New(
+		WithMetricsRoute(),
+		WithRuntimeMetrics(),
+		WithTelemetryConfig(telemetry.Config{}),
+	)
+	require.NoError(t, err)
+	require.Len(t, base.callbacks, 2)
+
+	rec := httptest.NewRecorder()
+	base.router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, MetricsPathPattern, nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	body, _ := io.ReadAll(rec.Body)
+	for _, name := range []string{
+		"go_goroutine_count",
+		"go_memory_used_bytes",
+		"process_cpu_time_seconds_total",
+		"process_memory_usage_bytes",
+		"process_thread_count",
+		"process_unix_file_descriptor_count",
+	} {
+		assert.Contains(t, string(body), name)
+	}
+
+	for _, reg := range base.callbacks {
+		require.NoError(t, reg.Unregister())
+	}
+	require.NoError(t, base.Telemetry().Shutdown(context.Background()))
+}
+
+func TestReadProcStat(t *testing.T) {
+	t.Run("valid", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "stat")
+		require.NoError(t, os.WriteFile(path, []byte(
+			"42 (my (app) name) S 1 42 42 0 -1 4194560 1000 0 0 0 250 50 0 0 20 0 7 0 100 1048576 300 18446744073709551615",
+		), 0o600))
+
+		have, err := readProcStat(path)
+		require.NoError(t, err)
+		assert.Equal(t, procStat{
+			userTime:   2.5,
+			systemTime: 0.5,
+			threads:    7,
+			vsize:      1048576,
+			rss:        300 * int64(os.Getpagesize()),
+		}, have)
+	})
+	t.Run("invalid", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "stat")
+		require.NoError(t, os.WriteFile(path, []byte("42 (app) S 1"), 0o600))
+
+		_, err := readProcStat(path)
+		assert.ErrorIs(t, err, ErrReadProcStat)
+	})
+	t.Run("missing", func(t *testing.T) {
+		_, err := readProcStat(filepath.Join(t.TempDir(), "stat"))
+		assert.ErrorIs(t, err, ErrReadProcStat)
+	})
+}
@@ -0,0 +1,290 @@
+// Copyright (c) 2026, Roel Schut. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package webapp
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/go-pogo/env"
+	"github.com/go-pogo/telemetry"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// maxTailTraces is the max amount of unsampled traces remembered at the
+	// same time. When full, expired traces or else the least recently added
+	// trace are forgotten.
+	maxTailTraces = 1024
+	// maxTailSpans is the max amount of spans buffered per unsampled trace.
+	maxTailSpans = 256
+	// tailTraceTTL is the duration after which a remembered unsampled trace
+	// may be forgotten.
+	tailTraceTTL = time.Minute
+)
+
+// SamplingConfig configures the sampling of traces, see [WithTraceSampling].
+type SamplingConfig struct {
+	// Ratio of traces to sample, between 0 and 1. Spans with a parent are
+	// sampled when their parent is sampled.
+	Ratio float64 `default:"0.5"`
+	// SampleErrors samples all traces which are not sampled by Ratio but
+	// contain a span with an error status.
+	SampleErrors bool `default:"true"`
+	// Routes overrides Ratio for requests to the routes with the listed names,
+	// regardless of the sampling decision of a remote parent. A ratio of 0
+	// never samples the route's requests, even when SampleErrors is true,
+	// where a ratio of 1 always samples them.
+	Routes map[string]float64
+}
+
+// WithTraceSampling samples traces according to conf instead of the sampler
+// set in the [telemetry.Config] of [WithTelemetryConfig]. When
+// [SamplingConfig].SampleErrors is true, spans of traces which are not
+// sampled are recorded and buffered until their local root span ends. The
+// spans are exported by the OTLP exporter and the span exporters of the
+// [telemetry.TracerProviderBuilder] when any of them has an error status,
+// otherwise they are discarded. [New] returns an error matching
+// [ErrSamplingWithTelemetry] when combined with [WithTelemetry].
+func WithTraceSampling(conf SamplingConfig) Option {
+	return func(_ *Base, config *config) error {
+		config.sampling = &conf
+		return nil
+	}
+}
+
+// newOTLPSpanExporter creates the OTLP span exporter configured by conf.
+var newOTLPSpanExporter = func(conf telemetry.Config) (sdktrace.SpanExporter, error) {
+	// the exporter reads its config from the environment
+	if err := env.Load(conf); err != nil {
+		return nil, err
+	}
+	return otlptracegrpc.New(context.Background())
+}
+
+// setupSampling configures builder to sample according to sc and sets up the
+// default exporters. When sc.SampleErrors is true, the OTLP span exporter and
+// the span exporters of the builder's [telemetry.TracerProviderBuilder] are
+// wrapped by a tail sampler, so the spans of failed traces are exported.
+func setupSampling(builder *telemetry.Builder, sc SamplingConfig) error {
+	builder.TracerProvider.WithSampler(newSampler(sc))
+	if !sc.SampleErrors {
+		builder.WithDefaultExporter()
+		return nil
+	}
+
+	// exporters are added by the tail sampler instead of the builder
+	exporters := builder.TracerProvider.SpanExporters
+	builder.TracerProvider.SpanExporters = nil
+
+	if otlp := builder.ExporterOTLP; otlp.Endpoint != "" && otlp.Protocol == "grpc" {
+		// WithDefaultExporter adds the OTLP span exporter using a batcher,
+		// which cannot be wrapped by the tail sampler
+		builder.MeterProvider.WithGrpcExporter()
+		exp, err := newOTLPSpanExporter(builder.Config)
+		if err != nil {
+			return err
+		}
+		exporters = append(exporters, exp)
+	}
+
+	builder.TracerProvider.With(tailSampledProcessors(exporters...)...)
+	return nil
+}
+
+// tailSampledProcessors returns an option per exporter which registers a
+// [sdktrace.BatchSpanProcessor] wrapped by a tail sampler.
+func tailSampledProcessors(exporters ...sdktrace.SpanExporter) []sdktrace.TracerProviderOption {
+	opts := make([]sdktrace.TracerProviderOption, 0, len(exporters))
+	for _, exp := range exporters {
+		opts = append(opts, sdktrace.WithSpanProcessor(
+			newTailSampler(sdktrace.NewBatchSpanProcessor(exp)),
+		))
+	}
+	return opts
+}
+
+var _ sdktrace.Sampler = (*sampler)(nil)
+
+type sampler struct {
+	ratio  sdktrace.Sampler
+	routes map[string]sdktrace.Sampler
+	record bool
+}
+
+func newSampler(conf SamplingConfig) *sampler {
+	s := &sampler{
+		ratio:  sdktrace.ParentBased(sdktrace.TraceIDRatioBased(conf.Ratio)),
+		record: conf.SampleErrors,
+	}
+	if len(conf.Routes) != 0 {
+		s.routes = make(map[string]sdktrace.Sampler, len(conf.Routes))
+		for name, ratio := range conf.Routes {
+			s.routes[name] = sdktrace.TraceIDRatioBased(ratio)
+		}
+	}
+	return s
+}
+
+func (s *sampler) ShouldSample(p sdktrace.SamplingParameters) sdktrace.SamplingResult {
+	parent := trace.SpanFromContext(p.ParentContext)
+	psc := parent.SpanContext()
+	localRoot := !psc.IsValid() || psc.IsRemote()
+
+	if localRoot && s.routes != nil {
+		if route, ok := routeFromContext(p.ParentContext); ok {
+			if rs, ok := s.routes[route.Name]; ok {
+				return rs.ShouldSample(p)
+			}
+		}
+	}
+
+	res := s.ratio.ShouldSample(p)
+	if res.Decision == sdktrace.Drop && s.record && (localRoot || parent.IsRecording()) {
+		// record the span so it can be exported when its trace has an error
+		res.Decision = sdktrace.RecordOnly
+	}
+	return res
+}
+
+func (s *sampler) Description() string { return "webapp.Sampler" }
+
+var _ sdktrace.SpanProcessor = (*tailSampler)(nil)
+
+// tailSampler buffers the ended spans of unsampled traces until the trace's
+// local root span ends. When any of the spans has an error status, all
+// buffered spans are passed to the next [sdktrace.SpanProcessor] as if they
+// were sampled. Sampled spans are passed directly. Spans which end after
+// their local root span are passed when the trace has failed, and are
+// discarded otherwise.
+type tailSampler struct {
+	next   sdktrace.SpanProcessor
+	mut    sync.Mutex
+	traces map[trace.TraceID]*tailTrace
+}
+
+type tailTrace struct {
+	spans   []sdktrace.ReadOnlySpan
+	failed  bool
+	flushed bool
+	added   time.Time
+}
+
+func newTailSampler(next sdktrace.SpanProcessor) *tailSampler {
+	return &tailSampler{
+		next:   next,
+		traces: make(map[trace.TraceID]*tailTrace),
+	}
+}
+
+func (ts *tailSampler) OnStart(parent context.Context, s sdktrace.ReadWriteSpan) {
+	if sc := s.SpanContext(); !sc.IsSampled() && isLocalRoot(s) {
+		// a new local root of a flushed trace, e.g. a second request within
+		// the same remote trace, buffers its spans again
+		ts.mut.Lock()
+		if tt, ok := ts.traces[sc.TraceID()]; ok && tt.flushed {
+			delete(ts.traces, sc.TraceID())
+		}
+		ts.mut.Unlock()
+	}
+	ts.next.OnStart(parent, s)
+}
+
+func (ts *tailSampler) OnEnd(s sdktrace.ReadOnlySpan) {
+	sc := s.SpanContext()
+	if sc.IsSampled() {
+		ts.next.OnEnd(s)
+		return
+	}
+
+	now := time.Now()
+	ts.mut.Lock()
+	tt, ok := ts.traces[sc.TraceID()]
+	if !ok {
+		if len(ts.traces) >= maxTailTraces {
+			ts.evict(now)
+		}
+		tt = &tailTrace{added: now}
+		ts.traces[sc.TraceID()] = tt
+	} else if tt.flushed {
+		failed := tt.failed
+		ts.mut.Unlock()
+		if failed {
+			ts.next.OnEnd(sampledSpan{s})
+		}
+		return
+	}
+	if len(tt.spans) < maxTailSpans {
+		tt.spans = append(tt.spans, s)
+	}
+	if s.Status().Code == codes.Error {
+		tt.failed = true
+	}
+	if !isLocalRoot(s) {
+		// wait for the local root span to end
+		ts.mut.Unlock()
+		return
+	}
+
+	// remember the flushed trace, so spans ending after the local root are
+	// not buffered
+	spans, failed := tt.spans, tt.failed
+	tt.spans, tt.flushed, tt.added = nil, true, now
+	ts.mut.Unlock()
+
+	if failed {
+		for _, span := range spans {
+			ts.next.OnEnd(sampledSpan{span})
+		}
+	}
+}
+
+// evict removes all expired traces, or the oldest trace when none are
+// expired. The buffered spans of removed traces are discarded.
+func (ts *tailSampler) evict(now time.Time) {
+	var oldest trace.TraceID
+	var oldestAdded time.Time
+	for id, tt := range ts.traces {
+		if now.Sub(tt.added) >= tailTraceTTL {
+			delete(ts.traces, id)
+			continue
+		}
+		if oldestAdded.IsZero() || tt.added.Before(oldestAdded) {
+			oldest, oldestAdded = id, tt.added
+		}
+	}
+	if len(ts.traces) >= maxTailTraces {
+		delete(ts.traces, oldest)
+	}
+}
+
+func (ts *tailSampler) Shutdown(ctx context.Context) error {
+	return ts.next.Shutdown(ctx)
+}
+
+func (ts *tailSampler) ForceFlush(ctx context.Context) error {
+	return ts.next.ForceFlush(ctx)
+}
+
+// isLocalRoot reports whether s is the local root span of its trace.
+func isLocalRoot(s sdktrace.ReadOnlySpan) bool {
+	psc := s.Parent()
+	return !psc.IsValid() || psc.IsRemote()
+}
+
+// sampledSpan marks the span as sampled, so it is exported by the
+// [sdktrace.SpanProcessor]s which ignore unsampled spans.
+type sampledSpan struct {
+	sdktrace.ReadOnlySpan
+}
+
+func (s sampledSpan) SpanContext() trace.SpanContext {
+	sc := s.ReadOnlySpan.SpanContext()
+	return sc.WithTraceFlags(sc.TraceFlags().WithSampled(true))
+}
@@ -0,0 +1,213 @@
+// Copyright (c) 2026, Roel Schut. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package webapp
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/go-pogo/errors"
+	"github.com/go-pogo/serv"
+	"github.com/go-pogo/telemetry"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// otlpTestConfig returns a [telemetry.Config] with an OTLP exporter, which is
+// replaced by exp for the duration of the test.
+func otlpTestConfig(t *testing.T, exp sdktrace.SpanExporter) telemetry.Config {
+	t.Helper()
+	orig := newOTLPSpanExporter
+	newOTLPSpanExporter = func(telemetry.Config) (sdktrace.SpanExporter, error) {
+		return exp, nil
+	}
+	t.Cleanup(func() { newOTLPSpanExporter = orig })
+
+	// restore the environment which is loaded with the config
+	for _, key := range []string{
+		"OTEL_SERVICE_NAME",
+		"OTEL_RESOURCE_ATTRIBUTES",
+		"OTEL_EXPORTER_OTLP_ENDPOINT",
+		"OTEL_EXPORTER_OTLP_PROTOCOL",
+		"OTEL_EXPORTER_OTLP_TIMEOUT",
+	} {
+		t.Setenv(key, os.Getenv(key))
+	}
+
+	return telemetry.Config{
+		ServiceName: "test",
+		ExporterOTLP: telemetry.ExporterOTLPConfig{
+			Endpoint: "localhost:4317",
+			Protocol: "grpc",
+		},
+	}
+}
+
+func TestWithTraceSampling(t *testing.T) {
+	failing := HandlerFunc(func(http.ResponseWriter, *http.Request) error {
+		return errors.New("some error")
+	})
+	ok := http.HandlerFunc(func(wri http.ResponseWriter, _ *http.Request) {
+		wri.WriteHeader(http.StatusOK)
+	})
+
+	exp := tracetest.NewInMemoryExporter()
+	base, err := New(
+		WithTelemetryConfig(otlpTestConfig(t, exp)),
+		WithIsolatedTelemetry(),
+		WithTraceSampling(SamplingConfig{
+			Ratio:        0,
+			SampleErrors: true,
+			Routes: map[string]float64{
+				"checkout": 1,
+				"ignored":  0,
+			},
+		}),
+		WithRoutesRegisterer(serv.RoutesRegistererFunc(func(rh serv.RouteHandler) {
+			rh.HandleRoute(serv.Route{Name: "items", Method: http.MethodGet, Pattern: "/items", Handler: ok})
+			rh.HandleRoute(serv.Route{Name: "fail", Method: http.MethodGet, Pattern: "/fail", Handler: failing})
+			rh.HandleRoute(serv.Route{Name: "checkout", Method: http.MethodGet, Pattern: "/checkout", Handler: ok})
+			rh.HandleRoute(serv.Route{Name: "ignored", Method: http.MethodGet, Pattern: "/ignored", Handler: failing})
+		})),
+	)
+	require.NoError(t, err)
+	// flush the spans only, the metrics are exported to an unavailable
+	// endpoint
+	tp := base.Telemetry().TracerProvider().(*sdktrace.TracerProvider)
+
+	tests := map[string]bool{
+		"/items":    false,
+		"/fail":     true,
+		"/checkout": true,
+		"/ignored":  false,
+	}
+	for target, sampled := range tests {
+		t.Run(target, func(t *testing.T) {
+			exp.Reset()
+			base.Server().Handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, target, nil))
+			require.NoError(t, tp.ForceFlush(context.Background()))
+
+			spans := exp.GetSpans()
+			if !sampled {
+				assert.Empty(t, spans)
+				return
+			}
+
+			require.Len(t, spans, 1)
+			assert.Equal(t, "GET "+target, spans[0].Name)
+			assert.True(t, spans[0].SpanContext.IsSampled())
+		})
+	}
+}
+
+func TestTailSampler(t *testing.T) {
+	exp := tracetest.NewInMemoryExporter()
+	ts := newTailSampler(sdktrace.NewSimpleSpanProcessor(exp))
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithSampler(newSampler(SamplingConfig{SampleErrors: true})),
+		sdktrace.WithSpanProcessor(ts),
+	)
+	tracer := tp.Tracer("test")
+
+	t.Run("without error", func(t *testing.T) {
+		exp.Reset()
+		ctx, root := tracer.Start(context.Background(), "root")
+		_, child := tracer.Start(ctx, "child")
+		child.End()
+		root.End()
+
+		assert.False(t, root.SpanContext().IsSampled())
+		assert.Empty(t, exp.GetSpans())
+	})
+	t.Run("with error", func(t *testing.T) {
+		exp.Reset()
+		ctx, root := tracer.Start(context.Background(), "root")
+		_, child := tracer.Start(ctx, "child")
+		endSpan(child, errors.New("some error"))
+
+		// spans are buffered until the root ends
+		assert.Empty(t, exp.GetSpans())
+		root.End()
+
+		spans := exp.GetSpans()
+		require.Len(t, spans, 2)
+		assert.Equal(t, "child", spans[0].Name)
+		assert.Equal(t, "root", spans[1].Name)
+		assert.Equal(t, trace.FlagsSampled, spans[1].SpanContext.TraceFlags())
+	})
+	t.Run("late span of failed trace", func(t *testing.T) {
+		exp.Reset()
+		ctx, root := tracer.Start(context.Background(), "root")
+		_, child := tracer.Start(ctx, "child")
+		endSpan(root, errors.New("some error"))
+		require.Len(t, exp.GetSpans(), 1)
+
+		child.End()
+		spans := exp.GetSpans()
+		require.Len(t, spans, 2)
+		assert.Equal(t, "child", spans[1].Name)
+	})
+	t.Run("late span of trace without error", func(t *testing.T) {
+		exp.Reset()
+		ctx, root := tracer.Start(context.Background(), "root")
+		_, child := tracer.Start(ctx, "child")
+		root.End()
+		child.End()
+
+		assert.Empty(t, exp.GetSpans())
+		ts.mut.Lock()
+		defer ts.mut.Unlock()
+		assert.Empty(t, ts.traces[root.SpanContext().TraceID()].spans)
+	})
+	t.Run("unfinished traces", func(t *testing.T) {
+		exp.Reset()
+		for i := 0; i < maxTailTraces+10; i++ {
+			ctx, _ := tracer.Start(context.Background(), "root")
+			_, child := tracer.Start(ctx, "child")
+			child.End()
+		}
+
+		ctx, root := tracer.Start(context.Background(), "root")
+		_, child := tracer.Start(ctx, "child")
+		endSpan(child, errors.New("some error"))
+		root.End()
+
+		assert.Len(t, exp.GetSpans(), 2)
+		ts.mut.Lock()
+		defer ts.mut.Unlock()
+		assert.LessOrEqual(t, len(ts.traces), maxTailTraces)
+	})
+}
+
+func TestSetupSampling(t *testing.T) {
+	exp := tracetest.NewInMemoryExporter()
+	builder := telemetry.NewBuilder(telemetry.Config{})
+	builder.TracerProvider.WithSpanExporters(exp)
+
+	require.NoError(t, setupSampling(builder, SamplingConfig{SampleErrors: true}))
+	assert.IsType(t, new(sampler), builder.TracerProvider.Sampler)
+	assert.Empty(t, builder.TracerProvider.SpanExporters, "exporters are wrapped by the tail sampler")
+}
+
+func TestRouter_resolveRoute(t *testing.T) {
+	mux := newRouter()
+	mux.HandleRoute(serv.Route{Name: "item", Method: http.MethodGet, Pattern: "/items/{id}", Handler: http.NotFoundHandler()})
+	require.NoError(t, mux.Err())
+
+	var route serv.Route
+	var found bool
+	mux.resolveRoute(http.HandlerFunc(func(_ http.ResponseWriter, req *http.Request) {
+		route, found = routeFromContext(req.Context())
+	})).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/items/1", nil))
+
+	assert.True(t, found)
+	assert.Equal(t, "item", route.Name)
+}
@@ -0,0 +1,140 @@
+// Copyright (c) 2026, Roel Schut. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package webapp
+
+import (
+	"github.com/go-pogo/errors"
+	"github.com/go-pogo/telemetry"
+	"go.opentelemetry.io/otel"
+	semconv "go.opentelemetry.io/otel/semconv/v1.40.0"
+)
+
+const (
+	// ErrMetricsWithoutTelemetry is returned by [New] when [WithMetricsRoute]
+	// is used without [WithTelemetryConfig].
+	ErrMetricsWithoutTelemetry errors.Msg = "metrics route requires telemetry config"
+	// ErrMetricsWithTelemetry is returned by [New] when [WithMetricsRoute] is
+	// used with [WithTelemetry], whose MeterProvider cannot export to the
+	// metrics route.
+	ErrMetricsWithTelemetry errors.Msg = "metrics route is not supported with provided telemetry"
+	// ErrSamplingWithTelemetry is returned by [New] when [WithTraceSampling]
+	// is used with [WithTelemetry], whose TracerProvider already has a
+	// sampler.
+	ErrSamplingWithTelemetry errors.Msg = "trace sampling is not supported with provided telemetry"
+)
+
+// WithIsolatedTelemetry keeps the telemetry providers built using the config
+// of [WithTelemetryConfig], and the LoggerProvider of [WithOTELLogs], local to
+// [Base]. They are passed explicitly to all instrumentation and the otel
+// globals, like the global providers, propagator, logger and error handler,
+// are never set. This allows multiple [Base] instances in the same process,
+// e.g. in parallel tests. The W3C tracecontext and baggage propagators are
+// used unless [WithPropagation] is used.
+func WithIsolatedTelemetry() Option {
+	return func(_ *Base, config *config) error {
+		config.isolated = true
+		return nil
+	}
+}
+
+// WithTelemetry enables tracing and metrics using the providers of telem,
+// instead of building them using the config of [WithTelemetryConfig]. The
+// providers are used as is, [New] returns an error matching
+// [ErrMetricsWithTelemetry] or [ErrSamplingWithTelemetry] when combined with
+// [WithMetricsRoute] or [WithTraceSampling]. Like [WithIsolatedTelemetry], the otel globals are never set. This is
+// mainly useful for testing, see package webapptest.
+func WithTelemetry(telem *telemetry.Telemetry) Option {
+	return func(base *Base, config *config) error {
+		base.telem = telem
+		base.router.trace = true
+		config.isolated = true
+		return nil
+	}
+}
+
+// setupTelemetry builds the [telemetry.Telemetry] configured with
+// [WithTelemetryConfig], unless it is provided using [WithTelemetry]. It is
+// called after all options are applied, so the order of options does not
+// matter. It returns an error matching [ErrMetricsWithoutTelemetry] or
+// [ErrMetricsWithTelemetry] when [WithMetricsRoute] is used without
+// [WithTelemetryConfig], or with [WithTelemetry]. Likewise, it returns an
+// error matching [ErrSamplingWithTelemetry] when [WithTraceSampling] is used
+// with [WithTelemetry].
+func (base *Base) setupTelemetry(conf *config) error {
+	if base.telem != nil && conf.sampling != nil {
+		return errors.New(ErrSamplingWithTelemetry)
+	}
+	if base.telem == nil && conf.telemetry != nil {
+		if err := base.buildTelemetry(conf); err != nil {
+			return err
+		}
+	} else if conf.metrics != nil {
+		// the registry is only filled by a MeterProvider built by
+		// buildTelemetry
+		if base.telem != nil {
+			return errors.New(ErrMetricsWithTelemetry)
+		}
+		return errors.New(ErrMetricsWithoutTelemetry)
+	}
+	if base.telem == nil {
+		return nil
+	}
+
+	base.router.meterProvider = base.telem.MeterProvider()
+	if conf.isolated {
+		if base.propagator == nil {
+			// do not fall back to the global propagator
+			base.propagator, _ = NewPropagator()
+		}
+	} else if base.propagator != nil {
+		otel.SetTextMapPropagator(base.propagator)
+	}
+	if err := base.registerLifecycleMetrics(conf); err != nil {
+		return err
+	}
+	if err := base.registerHealthMetrics(); err != nil {
+		return err
+	}
+	return base.setupRuntimeMetrics(conf)
+}
+
+func (base *Base) buildTelemetry(conf *config) error {
+	builder := telemetry.NewBuilder(*conf.telemetry)
+	if !conf.isolated {
+		builder.Global()
+		if conf.logger != nil {
+			conf.logger.SetOTELLogger()
+		}
+	}
+	if conf.sampling != nil {
+		if err := setupSampling(builder, *conf.sampling); err != nil {
+			return err
+		}
+	} else {
+		builder.WithDefaultExporter()
+	}
+	if base.build != nil {
+		if base.build.Version != "" {
+			builder.TracerProvider.WithAttributes(
+				semconv.ServiceVersion(base.build.Version),
+			)
+		}
+		builder.TracerProvider.WithBuildInfo(base.build.Internal())
+	}
+	if conf.metrics != nil {
+		builder.MeterProvider.WithPrometheusExporter(conf.metrics)
+	}
+	if conf.runtimeMetrics {
+		// runtime metrics are started by setupRuntimeMetrics
+		builder.MeterProvider.DisableRuntimeMetrics = true
+	}
+
+	// the LoggerProvider of WithOTELLogs describes the same resource
+	conf.resourceAttrs = builder.TracerProvider.Attributes
+
+	var err error
+	base.telem, err = builder.Build()
+	return err
+}
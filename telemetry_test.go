@@ -0,0 +1,68 @@
+// Copyright (c) 2026, Roel Schut. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package webapp
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-pogo/serv"
+	"github.com/go-pogo/telemetry"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/log/global"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestWithIsolatedTelemetry(t *testing.T) {
+	tp := otel.GetTracerProvider()
+	mp := otel.GetMeterProvider()
+	lp := global.GetLoggerProvider()
+	prop := otel.GetTextMapPropagator()
+
+	newBase := func(traceID *trace.TraceID) *Base {
+		base, err := New(
+			WithTelemetryConfig(telemetry.Config{}),
+			WithIsolatedTelemetry(),
+			WithOTELLogs(),
+			WithRoutesRegisterer(serv.RoutesRegistererFunc(func(rh serv.RouteHandler) {
+				rh.HandleRoute(serv.Route{
+					Name:    "item",
+					Method:  http.MethodGet,
+					Pattern: "/item",
+					Handler: http.HandlerFunc(func(_ http.ResponseWriter, req *http.Request) {
+						*traceID = trace.SpanContextFromContext(req.Context()).TraceID()
+					}),
+				})
+			})),
+		)
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = base.Shutdown(context.Background()) })
+		return base
+	}
+
+	var traceID1, traceID2 trace.TraceID
+	base1 := newBase(&traceID1)
+	base2 := newBase(&traceID2)
+
+	assert.Same(t, tp, otel.GetTracerProvider())
+	assert.Same(t, mp, otel.GetMeterProvider())
+	assert.Same(t, lp, global.GetLoggerProvider())
+	assert.NotSame(t, base1.LoggerProvider(), base2.LoggerProvider())
+	assert.Equal(t, prop, otel.GetTextMapPropagator())
+	assert.NotSame(t, base1.Telemetry().TracerProvider(), base2.Telemetry().TracerProvider())
+	assert.NotSame(t, base1.Telemetry().MeterProvider(), base2.Telemetry().MeterProvider())
+
+	req := httptest.NewRequest(http.MethodGet, "/item", nil)
+	req.Header.Set("Traceparent", "00-0102030405060708090a0b0c0d0e0f10-0102030405060708-01")
+	base1.Server().Handler.ServeHTTP(httptest.NewRecorder(), req)
+	base2.Server().Handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	assert.Equal(t, "0102030405060708090a0b0c0d0e0f10", traceID1.String())
+	assert.Equal(t, "0102030405060708090a0b0c0d0e0f10", traceID2.String())
+}
@@ -0,0 +1,217 @@
+// Copyright (c) 2026, Roel Schut. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package webapp
+
+import (
+	"context"
+	stdlog "log"
+	"net/http"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"github.com/go-pogo/errors"
+	"github.com/go-pogo/webapp/logger"
+	"github.com/go-pogo/webapp/problem"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const ErrRouteTimeout errors.Msg = "route timeout exceeded"
+
+// timeoutRoute wraps handler so it is served with a request context which is
+// canceled after the route's timeout, or the router's default timeout.
+func (mux *router) timeoutRoute(meta RouteMeta, handler http.Handler) http.Handler {
+	if meta.Streaming || meta.Timeout < 0 {
+		return handler
+	}
+
+	return http.HandlerFunc(func(wri http.ResponseWriter, req *http.Request) {
+		timeout := meta.Timeout
+		if timeout == 0 {
+			timeout = time.Duration(mux.timeout.Load())
+		}
+		if timeout <= 0 {
+			handler.ServeHTTP(wri, req)
+			return
+		}
+		serveWithTimeout(wri, req, handler, timeout, mux.panicLog)
+	})
+}
+
+// statusClientClosedRequest is the non-standard status code of a request
+// which is canceled by the client before a response is sent.
+const statusClientClosedRequest = 499
+
+// serveWithTimeout serves req using handler. Its response is written through
+// to wri, only headers are buffered until they are sent. When handler does not
+// finish within timeout, an [ErrRouteTimeout] error is handled using
+// [HandleError], which results in a 503 response. When the response's headers
+// are already sent, the response is aborted instead. Panics of handler after
+// the timeout are logged using log.
+func serveWithTimeout(wri http.ResponseWriter, req *http.Request, handler http.Handler, timeout time.Duration, log problem.PanicLogger) {
+	ctx, cancel := context.WithTimeout(req.Context(), timeout)
+	defer cancel()
+
+	tw := &timeoutWriter{wri: wri, header: make(http.Header)}
+	done := make(chan struct{})
+	panicChan := make(chan any, 1)
+
+	go func() {
+		defer func() {
+			p := recover()
+			if p == nil {
+				return
+			}
+
+			tw.mut.Lock()
+			late := tw.timedOut
+			tw.mut.Unlock()
+			if !late {
+				panicChan <- p
+			} else if p != http.ErrAbortHandler {
+				logLatePanic(ctx, log, p, req)
+			}
+		}()
+		handler.ServeHTTP(tw, req.WithContext(ctx))
+		close(done)
+	}()
+
+	select {
+	case p := <-panicChan:
+		panic(p)
+
+	case <-done:
+		tw.finish()
+
+	case <-ctx.Done():
+		tw.mut.Lock()
+		defer tw.mut.Unlock()
+		tw.timedOut = true
+
+		select {
+		case p := <-panicChan:
+			panic(p)
+		case <-done:
+			// handler finished at the same time as the context
+			tw.writeHeader(http.StatusOK)
+			return
+		default:
+		}
+
+		err := ctx.Err()
+		if !errors.Is(err, context.DeadlineExceeded) {
+			// the client canceled the request, it does not receive a response
+			if !tw.wroteHeader {
+				wri.WriteHeader(statusClientClosedRequest)
+			}
+			return
+		}
+
+		trace.SpanFromContext(ctx).AddEvent("timeout", trace.WithAttributes(
+			attribute.String("webapp.route.timeout", timeout.String()),
+		))
+		if rd := logger.RequestDetailsFromContext(ctx); rd != nil {
+			rd.Timeout = timeout
+		}
+		if tw.wroteHeader {
+			// abort the incomplete response, so the client does not mistake
+			// it for a complete response
+			panic(http.ErrAbortHandler)
+		}
+		HandleError(wri, req, errors.WithStatusCode(
+			errors.Wrap(err, ErrRouteTimeout),
+			http.StatusServiceUnavailable,
+		))
+	}
+}
+
+// logLatePanic logs panic p of a handler which panicked after its timeout,
+// when its response is already sent.
+func logLatePanic(ctx context.Context, log problem.PanicLogger, p any, req *http.Request) {
+	stack := debug.Stack()
+	if log != nil {
+		log.LogPanic(ctx, p, stack, req)
+		return
+	}
+	stdlog.Printf("webapp: panic after route timeout serving %s: %v\n%s", req.URL.Path, p, stack)
+}
+
+var _ http.Flusher = (*timeoutWriter)(nil)
+
+// timeoutWriter writes the response of a handler served by serveWithTimeout.
+// Headers are buffered until they are sent, after the timeout the handler can
+// no longer write to the response.
+type timeoutWriter struct {
+	wri         http.ResponseWriter
+	mut         sync.Mutex
+	header      http.Header
+	wroteHeader bool
+	timedOut    bool
+}
+
+func (tw *timeoutWriter) Header() http.Header { return tw.header }
+
+func (tw *timeoutWriter) Write(p []byte) (int, error) {
+	tw.mut.Lock()
+	defer tw.mut.Unlock()
+	if tw.timedOut {
+		return 0, http.ErrHandlerTimeout
+	}
+	tw.writeHeader(http.StatusOK)
+	return tw.wri.Write(p)
+}
+
+func (tw *timeoutWriter) WriteHeader(code int) {
+	tw.mut.Lock()
+	defer tw.mut.Unlock()
+	if !tw.timedOut {
+		tw.writeHeader(code)
+	}
+}
+
+// Flush is part of the [http.Flusher] interface.
+func (tw *timeoutWriter) Flush() {
+	tw.mut.Lock()
+	defer tw.mut.Unlock()
+	if tw.timedOut {
+		return
+	}
+	tw.writeHeader(http.StatusOK)
+	_ = http.NewResponseController(tw.wri).Flush()
+}
+
+// Unwrap returns the underlying [http.ResponseWriter], so it can be used by
+// [http.ResponseController].
+func (tw *timeoutWriter) Unwrap() http.ResponseWriter { return tw.wri }
+
+// finish sends the buffered headers of a handler which did not write a
+// response.
+func (tw *timeoutWriter) finish() {
+	tw.mut.Lock()
+	tw.writeHeader(http.StatusOK)
+	tw.mut.Unlock()
+}
+
+// writeHeader sends the buffered headers with code, when not already sent.
+// Headers of informational responses are sent without marking the headers as
+// sent. The caller must hold tw.mut.
+func (tw *timeoutWriter) writeHeader(code int) {
+	if tw.wroteHeader {
+		return
+	}
+
+	dst := tw.wri.Header()
+	for k, v := range tw.header {
+		dst[k] = v
+	}
+	if code < 200 && code != http.StatusSwitchingProtocols {
+		tw.wri.WriteHeader(code)
+		return
+	}
+
+	tw.wroteHeader = true
+	tw.wri.WriteHeader(code)
+}
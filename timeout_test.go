@@ -0,0 +1,159 @@
+// Copyright (c) 2026, Roel Schut. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package webapp
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-pogo/serv"
+	"github.com/go-pogo/webapp/problem"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithRouteTimeout(t *testing.T) {
+	slow := http.HandlerFunc(func(wri http.ResponseWriter, req *http.Request) {
+		select {
+		case <-req.Context().Done():
+		case <-time.After(50 * time.Millisecond):
+			wri.WriteHeader(http.StatusTeapot)
+		}
+	})
+	fast := http.HandlerFunc(func(wri http.ResponseWriter, _ *http.Request) {
+		wri.Header().Set("X-Test", "fast")
+		wri.WriteHeader(http.StatusCreated)
+		_, _ = wri.Write([]byte("done"))
+	})
+
+	base, err := New(
+		WithRouteTimeout(10*time.Millisecond),
+		WithRoutesRegisterer(serv.RoutesRegistererFunc(func(rh serv.RouteHandler) {
+			rh.HandleRoute(serv.Route{Name: "slow", Pattern: "/slow", Handler: slow})
+			rh.HandleRoute(serv.Route{Name: "fast", Pattern: "/fast", Handler: fast})
+			rh.HandleRoute(WithRouteMeta(
+				serv.Route{Name: "longer", Pattern: "/longer", Handler: slow},
+				RouteMeta{Timeout: time.Second},
+			))
+			rh.HandleRoute(WithRouteMeta(
+				serv.Route{Name: "stream", Pattern: "/stream", Handler: slow},
+				RouteMeta{Streaming: true},
+			))
+		})),
+	)
+	require.NoError(t, err)
+
+	serve := func(target string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		base.Server().Handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+		return rec
+	}
+
+	t.Run("timeout", func(t *testing.T) {
+		rec := serve("/slow")
+		assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+		assert.Equal(t, problem.ContentType, rec.Header().Get("Content-Type"))
+	})
+	t.Run("within timeout", func(t *testing.T) {
+		rec := serve("/fast")
+		assert.Equal(t, http.StatusCreated, rec.Code)
+		assert.Equal(t, "fast", rec.Header().Get("X-Test"))
+		assert.Equal(t, "done", rec.Body.String())
+	})
+	t.Run("route timeout", func(t *testing.T) {
+		assert.Equal(t, http.StatusTeapot, serve("/longer").Code)
+	})
+	t.Run("streaming", func(t *testing.T) {
+		assert.Equal(t, http.StatusTeapot, serve("/stream").Code)
+	})
+}
+
+type latePanicLogger struct {
+	Logger
+	panics chan any
+}
+
+func (l *latePanicLogger) LogRegisterRoute(serv.Route) {}
+
+func (l *latePanicLogger) LogPanic(_ context.Context, v any, _ []byte, _ *http.Request) {
+	l.panics <- v
+}
+
+func TestServeWithTimeout(t *testing.T) {
+	const timeout = 10 * time.Millisecond
+	newRequest := func() *http.Request {
+		return httptest.NewRequest(http.MethodGet, "/", nil)
+	}
+
+	t.Run("flush", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		serveWithTimeout(rec, newRequest(), http.HandlerFunc(func(wri http.ResponseWriter, _ *http.Request) {
+			wri.Header().Set("X-Test", "flush")
+			_, _ = wri.Write([]byte("partial"))
+			wri.(http.Flusher).Flush()
+		}), timeout, nil)
+
+		assert.True(t, rec.Flushed)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "flush", rec.Header().Get("X-Test"))
+		assert.Equal(t, "partial", rec.Body.String())
+	})
+	t.Run("unwrap", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		serveWithTimeout(rec, newRequest(), http.HandlerFunc(func(wri http.ResponseWriter, _ *http.Request) {
+			assert.Same(t, rec, wri.(interface{ Unwrap() http.ResponseWriter }).Unwrap())
+		}), timeout, nil)
+	})
+	t.Run("headers only", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		serveWithTimeout(rec, newRequest(), http.HandlerFunc(func(wri http.ResponseWriter, _ *http.Request) {
+			wri.Header().Set("X-Test", "headers")
+		}), timeout, nil)
+
+		assert.Equal(t, "headers", rec.Header().Get("X-Test"))
+	})
+	t.Run("timeout after headers are sent", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		assert.PanicsWithValue(t, http.ErrAbortHandler, func() {
+			serveWithTimeout(rec, newRequest(), http.HandlerFunc(func(wri http.ResponseWriter, req *http.Request) {
+				wri.WriteHeader(http.StatusAccepted)
+				<-req.Context().Done()
+			}), timeout, nil)
+		})
+		assert.Equal(t, http.StatusAccepted, rec.Code)
+	})
+	t.Run("client canceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		rec := httptest.NewRecorder()
+		serveWithTimeout(rec, newRequest().WithContext(ctx), http.HandlerFunc(func(_ http.ResponseWriter, req *http.Request) {
+			<-req.Context().Done()
+		}), time.Minute, nil)
+
+		assert.Equal(t, statusClientClosedRequest, rec.Code)
+		assert.Empty(t, rec.Body.String())
+	})
+	t.Run("late panic", func(t *testing.T) {
+		log := &latePanicLogger{panics: make(chan any, 1)}
+		rec := httptest.NewRecorder()
+		serveWithTimeout(rec, newRequest(), http.HandlerFunc(func(_ http.ResponseWriter, req *http.Request) {
+			<-req.Context().Done()
+			time.Sleep(timeout)
+			panic("late")
+		}), timeout, log)
+
+		assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+		select {
+		case v := <-log.panics:
+			assert.Equal(t, "late", v)
+		case <-time.After(time.Second):
+			t.Fatal("late panic is not logged")
+		}
+	})
+}
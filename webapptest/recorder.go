@@ -0,0 +1,250 @@
+// Copyright (c) 2026, Roel Schut. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package webapptest provides an in-memory telemetry recorder to test the
+// spans and metrics created by a [webapp.Base] and its handlers.
+package webapptest
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/go-pogo/telemetry"
+	"github.com/go-pogo/webapp"
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.40.0"
+)
+
+// Recorder records all spans and metrics of the telemetry providers it
+// installs into a [webapp.Base] using [WithRecorder]. All spans are sampled.
+// Unlike the exporters of [tracetest], recorded spans are kept when the
+// providers are shut down by [webapp.Base.Shutdown]. Metrics however can only
+// be collected before that.
+type Recorder struct {
+	spans  *spanRecorder
+	reader *sdkmetric.ManualReader
+	telem  *telemetry.Telemetry
+}
+
+// NewRecorder returns a new [Recorder] with its own tracer and meter
+// providers.
+func NewRecorder() *Recorder {
+	rec := Recorder{
+		spans:  new(spanRecorder),
+		reader: sdkmetric.NewManualReader(),
+	}
+	rec.telem = telemetry.New(
+		sdkmetric.NewMeterProvider(sdkmetric.WithReader(rec.reader)),
+		sdktrace.NewTracerProvider(
+			sdktrace.WithSampler(sdktrace.AlwaysSample()),
+			sdktrace.WithSpanProcessor(rec.spans),
+		),
+	)
+	return &rec
+}
+
+// WithRecorder returns a [webapp.Option] which enables telemetry using the
+// providers of rec, see [webapp.WithTelemetry]. The otel globals are never
+// set, so multiple [webapp.Base] instances can be tested in parallel, each
+// with their own [Recorder]. All spans are sampled, [webapp.WithTraceSampling]
+// and [webapp.WithMetricsRoute] cannot be combined with it.
+func WithRecorder(rec *Recorder) webapp.Option {
+	return webapp.WithTelemetry(rec.telem)
+}
+
+// Telemetry returns the [telemetry.Telemetry] with the providers of rec.
+func (rec *Recorder) Telemetry() *telemetry.Telemetry { return rec.telem }
+
+// Spans returns all ended spans, in the order they ended.
+func (rec *Recorder) Spans() tracetest.SpanStubs {
+	return tracetest.SpanStubsFromReadOnlySpans(rec.spans.ended())
+}
+
+// FindSpan returns the first ended span with name, and reports whether it was
+// found.
+func (rec *Recorder) FindSpan(name string) (tracetest.SpanStub, bool) {
+	for _, span := range rec.spans.ended() {
+		if span.Name() == name {
+			return tracetest.SpanStubFromReadOnlySpan(span), true
+		}
+	}
+	return tracetest.SpanStub{}, false
+}
+
+// RouteSpans returns all ended spans with an [semconv.HTTPRouteKey] attribute
+// of route, as set by the router of [webapp.Base] on the spans of requests
+// to its routes.
+func (rec *Recorder) RouteSpans(route string) tracetest.SpanStubs {
+	var res tracetest.SpanStubs
+	for _, span := range rec.Spans() {
+		if r, ok := Route(span); ok && r == route {
+			res = append(res, span)
+		}
+	}
+	return res
+}
+
+// Reset removes all recorded spans. Metrics are cumulative and are not
+// affected.
+func (rec *Recorder) Reset() { rec.spans.reset() }
+
+// Metrics collects and returns all metrics recorded by the MeterProvider.
+func (rec *Recorder) Metrics() ([]metricdata.Metrics, error) {
+	var rm metricdata.ResourceMetrics
+	if err := rec.reader.Collect(context.Background(), &rm); err != nil {
+		return nil, err
+	}
+
+	var res []metricdata.Metrics
+	for _, sm := range rm.ScopeMetrics {
+		res = append(res, sm.Metrics...)
+	}
+	return res, nil
+}
+
+// MetricValue collects the metric with name and returns the sum of the values
+// of its data points which have all of attrs as attributes. For histograms
+// the number of recorded measurements is returned. It reports false when the
+// metric, or a data point with attrs, is not found.
+func (rec *Recorder) MetricValue(name string, attrs ...attribute.KeyValue) (float64, bool) {
+	metrics, err := rec.Metrics()
+	if err != nil {
+		return 0, false
+	}
+
+	for _, m := range metrics {
+		if m.Name == name {
+			return dataValue(m.Data, attrs)
+		}
+	}
+	return 0, false
+}
+
+func dataValue(data metricdata.Aggregation, attrs []attribute.KeyValue) (float64, bool) {
+	switch data := data.(type) {
+	case metricdata.Sum[int64]:
+		return sumDataPoints(data.DataPoints, attrs)
+	case metricdata.Sum[float64]:
+		return sumDataPoints(data.DataPoints, attrs)
+	case metricdata.Gauge[int64]:
+		return sumDataPoints(data.DataPoints, attrs)
+	case metricdata.Gauge[float64]:
+		return sumDataPoints(data.DataPoints, attrs)
+	case metricdata.Histogram[int64]:
+		return countHistogramDataPoints(data.DataPoints, attrs)
+	case metricdata.Histogram[float64]:
+		return countHistogramDataPoints(data.DataPoints, attrs)
+	}
+	return 0, false
+}
+
+func sumDataPoints[N int64 | float64](dps []metricdata.DataPoint[N], attrs []attribute.KeyValue) (float64, bool) {
+	var sum float64
+	var found bool
+	for _, dp := range dps {
+		if hasAttributes(dp.Attributes, attrs) {
+			sum += float64(dp.Value)
+			found = true
+		}
+	}
+	return sum, found
+}
+
+func countHistogramDataPoints[N int64 | float64](dps []metricdata.HistogramDataPoint[N], attrs []attribute.KeyValue) (float64, bool) {
+	var count uint64
+	var found bool
+	for _, dp := range dps {
+		if hasAttributes(dp.Attributes, attrs) {
+			count += dp.Count
+			found = true
+		}
+	}
+	return float64(count), found
+}
+
+func hasAttributes(set attribute.Set, attrs []attribute.KeyValue) bool {
+	for _, attr := range attrs {
+		if v, ok := set.Value(attr.Key); !ok || v != attr.Value {
+			return false
+		}
+	}
+	return true
+}
+
+// Route returns the value of the [semconv.HTTPRouteKey] attribute of span,
+// and reports whether it is set.
+func Route(span tracetest.SpanStub) (string, bool) {
+	for _, attr := range span.Attributes {
+		if attr.Key == semconv.HTTPRouteKey {
+			return attr.Value.AsString(), true
+		}
+	}
+	return "", false
+}
+
+// AssertRoute reports an error to t when the [semconv.HTTPRouteKey] attribute
+// of span is not route. It returns whether the assertion succeeded.
+func AssertRoute(t testing.TB, span tracetest.SpanStub, route string) bool {
+	t.Helper()
+	got, ok := Route(span)
+	if !ok {
+		t.Errorf("span %q has no %s attribute, expected %q", span.Name, semconv.HTTPRouteKey, route)
+		return false
+	}
+	if got != route {
+		t.Errorf("span %q has %s attribute %q, expected %q", span.Name, semconv.HTTPRouteKey, got, route)
+		return false
+	}
+	return true
+}
+
+// AssertRouteSpan reports an error to t when rec has not recorded any span
+// with a [semconv.HTTPRouteKey] attribute of route. It returns whether the
+// assertion succeeded.
+func AssertRouteSpan(t testing.TB, rec *Recorder, route string) bool {
+	t.Helper()
+	if len(rec.RouteSpans(route)) == 0 {
+		t.Errorf("no span with %s attribute %q recorded", semconv.HTTPRouteKey, route)
+		return false
+	}
+	return true
+}
+
+var _ sdktrace.SpanProcessor = (*spanRecorder)(nil)
+
+// spanRecorder is a [sdktrace.SpanProcessor] which stores ended spans in
+// memory, similar to [tracetest.SpanRecorder] but it can be reset.
+type spanRecorder struct {
+	mut   sync.Mutex
+	spans []sdktrace.ReadOnlySpan
+}
+
+func (sr *spanRecorder) OnStart(context.Context, sdktrace.ReadWriteSpan) {}
+
+func (sr *spanRecorder) OnEnd(s sdktrace.ReadOnlySpan) {
+	sr.mut.Lock()
+	sr.spans = append(sr.spans, s)
+	sr.mut.Unlock()
+}
+
+func (sr *spanRecorder) ended() []sdktrace.ReadOnlySpan {
+	sr.mut.Lock()
+	defer sr.mut.Unlock()
+	return append(make([]sdktrace.ReadOnlySpan, 0, len(sr.spans)), sr.spans...)
+}
+
+func (sr *spanRecorder) reset() {
+	sr.mut.Lock()
+	sr.spans = nil
+	sr.mut.Unlock()
+}
+
+func (sr *spanRecorder) Shutdown(context.Context) error { return nil }
+
+func (sr *spanRecorder) ForceFlush(context.Context) error { return nil }
@@ -0,0 +1,107 @@
+// Copyright (c) 2026, Roel Schut. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package webapptest
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-pogo/serv"
+	"github.com/go-pogo/webapp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	semconv "go.opentelemetry.io/otel/semconv/v1.40.0"
+)
+
+func TestRecorder(t *testing.T) {
+	tp := otel.GetTracerProvider()
+
+	rec := NewRecorder()
+	base, err := webapp.New(
+		WithRecorder(rec),
+		webapp.WithRoutesRegisterer(serv.RoutesRegistererFunc(func(rh serv.RouteHandler) {
+			rh.HandleRoute(serv.Route{
+				Name:    "item",
+				Method:  http.MethodGet,
+				Pattern: "/items/{id}",
+				Handler: http.NotFoundHandler(),
+			})
+		})),
+	)
+	require.NoError(t, err)
+	assert.Same(t, tp, otel.GetTracerProvider())
+
+	_, ok := rec.FindSpan("webapp.New")
+	assert.True(t, ok)
+
+	rec.Reset()
+	assert.Empty(t, rec.Spans())
+
+	for _, id := range []string{"1", "2"} {
+		req := httptest.NewRequest(http.MethodGet, "/items/"+id, nil)
+		base.Server().Handler.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	assert.Len(t, rec.Spans(), 2)
+	span, ok := rec.FindSpan("GET /items/{id}")
+	require.True(t, ok)
+	assert.True(t, AssertRoute(t, span, "/items/{id}"))
+	assert.True(t, AssertRouteSpan(t, rec, "/items/{id}"))
+	assert.Len(t, rec.RouteSpans("/items/{id}"), 2)
+	assert.Empty(t, rec.RouteSpans("/other"))
+
+	_, ok = rec.FindSpan("unknown")
+	assert.False(t, ok)
+
+	t.Run("metric value", func(t *testing.T) {
+		val, ok := rec.MetricValue("http.server.request.duration", semconv.HTTPRoute("/items/{id}"))
+		assert.True(t, ok)
+		assert.Equal(t, float64(2), val)
+
+		val, ok = rec.MetricValue(webapp.InfoMetricName)
+		assert.True(t, ok)
+		assert.Equal(t, float64(1), val)
+
+		_, ok = rec.MetricValue("http.server.request.duration", semconv.HTTPRoute("/other"))
+		assert.False(t, ok)
+		_, ok = rec.MetricValue("unknown")
+		assert.False(t, ok)
+	})
+
+	// the server is not started
+	_ = base.Shutdown(context.Background())
+	_, ok = rec.FindSpan("webapp.lifecycle")
+	assert.True(t, ok, "spans are kept after shutdown")
+}
+
+func TestWithRecorder(t *testing.T) {
+	t.Run("metrics route", func(t *testing.T) {
+		_, err := webapp.New(WithRecorder(NewRecorder()), webapp.WithMetricsRoute())
+		assert.ErrorIs(t, err, webapp.ErrMetricsWithTelemetry)
+	})
+	t.Run("trace sampling", func(t *testing.T) {
+		_, err := webapp.New(WithRecorder(NewRecorder()), webapp.WithTraceSampling(webapp.SamplingConfig{
+			Routes: map[string]float64{"item": 0},
+		}))
+		assert.ErrorIs(t, err, webapp.ErrSamplingWithTelemetry)
+	})
+}
+
+func TestAssertRoute(t *testing.T) {
+	rec := NewRecorder()
+	_, span := rec.Telemetry().TracerProvider().Tracer("test").Start(context.Background(), "span")
+	span.End()
+
+	stub, ok := rec.FindSpan("span")
+	require.True(t, ok)
+
+	mock := new(testing.T)
+	assert.False(t, AssertRoute(mock, stub, "/"))
+	assert.False(t, AssertRouteSpan(mock, rec, "/"))
+	assert.True(t, mock.Failed())
+}